@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"tracerstudy-post-service/common/config"
+
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/logger"
+	"tracerstudy-post-service/common/mysql"
+	"tracerstudy-post-service/common/storage"
+
+	"tracerstudy-post-service/modules/post/repository"
+	"tracerstudy-post-service/modules/post/service"
+)
+
+// backfill-placeholders computes the blurhash and dominant color of post images
+// uploaded before placeholders were generated on upload.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only list the posts that would be updated")
+	flag.Parse()
+
+	cfg, cerr := config.NewConfig(".env")
+	checkError(cerr)
+
+	logger.Init(cfg.Log)
+
+	dsn, derr := mysql.NewPool(&cfg.MySQL)
+	checkError(derr)
+
+	db, gerr := gormConn.NewMySQLGormDB(dsn, &cfg.Log)
+	checkError(gerr)
+
+	store, serr := storage.NewStorage(*cfg)
+	checkError(serr)
+
+	// placeholders are derived from the stored image, the posts are not edited, so they
+	// are written straight to the repository without touching updated_at or the audit log
+	ctx := context.Background()
+	postRepo := repository.NewPostRepository(db)
+	imageSvc := service.NewImageService(*cfg, store)
+
+	posts, err := postRepo.FindMissingPlaceholders(ctx)
+	checkError(err)
+
+	var updated, failed int
+	for _, post := range posts {
+		blurhash, dominantColor, err := imageSvc.GeneratePlaceholder(ctx, post.ImagePath)
+		if err != nil {
+			slog.ErrorContext(ctx, "[Backfill Placeholders] Error while generate placeholder", "post_id", post.Id, "image", post.ImagePath, "error", err)
+			failed++
+			continue
+		}
+
+		if *dryRun {
+			slog.InfoContext(ctx, "[Backfill Placeholders] Placeholder generated", "post_id", post.Id, "blurhash", blurhash, "color", dominantColor)
+			updated++
+			continue
+		}
+
+		if err := postRepo.UpdatePlaceholder(ctx, post.Id, blurhash, dominantColor); err != nil {
+			slog.ErrorContext(ctx, "[Backfill Placeholders] Error while update post", "post_id", post.Id, "error", err)
+			failed++
+			continue
+		}
+		updated++
+	}
+
+	slog.InfoContext(ctx, "[Backfill Placeholders] Backfill finished", "updated", updated, "total", len(posts), "failed", failed, "dry_run", *dryRun)
+}
+
+func checkError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
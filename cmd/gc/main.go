@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/gc"
+
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/logger"
+	"tracerstudy-post-service/common/mysql"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+
+	auditBuilder "tracerstudy-post-service/modules/audit/builder"
+	mediaRepository "tracerstudy-post-service/modules/media/repository"
+	mediaService "tracerstudy-post-service/modules/media/service"
+	"tracerstudy-post-service/modules/post/repository"
+	"tracerstudy-post-service/modules/post/service"
+)
+
+// gc reports and removes stored files that no post or media record references anymore.
+func main() {
+	dryRun := flag.Bool("dry-run", true, "only report orphans, set -dry-run=false to delete them")
+	grace := flag.Duration("grace", 0, "override GC_GRACE_PERIOD, orphans younger than this are kept, 0 keeps none")
+	flag.Parse()
+
+	cfg, cerr := config.NewConfig(".env")
+	checkError(cerr)
+
+	logger.Init(cfg.Log)
+
+	dsn, derr := mysql.NewPool(&cfg.MySQL)
+	checkError(derr)
+
+	db, gerr := gormConn.NewMySQLGormDB(dsn, &cfg.Log)
+	checkError(gerr)
+
+	store, serr := storage.NewStorage(*cfg)
+	checkError(serr)
+
+	mediaSigner, merr := signer.NewMediaSigner(*cfg)
+	checkError(merr)
+
+	postSvc := service.NewPostService(*cfg, repository.NewPostRepository(db), auditBuilder.BuildAuditService(*cfg, db), nil)
+	mediaSvc := mediaService.NewMediaService(*cfg, mediaRepository.NewMediaRepository(db), store, mediaSigner)
+	collector := gc.NewCollector(*cfg, store, postSvc, mediaSvc)
+	// only an explicit -grace overrides the setting, so -grace=0 is honored too
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "grace" {
+			collector.WithGracePeriod(*grace)
+		}
+	})
+
+	report, err := collector.Run(context.Background(), *dryRun)
+	checkError(err)
+
+	for _, obj := range report.Orphans {
+		slog.Info("[GC] Orphan", "key", obj.Key, "size", obj.Size, "modified", obj.ModTime)
+	}
+	slog.Info("[GC] Collection finished",
+		"scanned", report.Scanned, "referenced", report.Referenced, "recent", len(report.Recent), "orphans", len(report.Orphans),
+		"deleted", report.Deleted, "failed", report.Failed, "dry_run", report.DryRun)
+}
+
+func checkError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -1,18 +1,44 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"strings"
+	"tracerstudy-post-service/common/authorization"
 	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/gc"
+	"tracerstudy-post-service/common/metrics"
 
 	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/logger"
 	commonJwt "tracerstudy-post-service/common/jwt"
 	"tracerstudy-post-service/common/mysql"
+	"tracerstudy-post-service/common/ratelimit"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+	"tracerstudy-post-service/common/tracing"
+	"tracerstudy-post-service/common/validation"
 	"tracerstudy-post-service/server"
+	"tracerstudy-post-service/server/interceptor"
 
+	auditModule "tracerstudy-post-service/modules/audit"
+	auditBuilder "tracerstudy-post-service/modules/audit/builder"
 	postModule "tracerstudy-post-service/modules/post"
 	commentModule "tracerstudy-post-service/modules/comment"
+	commentRepository "tracerstudy-post-service/modules/comment/repository"
+	mediaModule "tracerstudy-post-service/modules/media"
+	mediaRepository "tracerstudy-post-service/modules/media/repository"
+	mediaService "tracerstudy-post-service/modules/media/service"
+	postRepository "tracerstudy-post-service/modules/post/repository"
+	permissionModule "tracerstudy-post-service/modules/permission"
+	revocationModule "tracerstudy-post-service/modules/revocation"
+	revocationBuilder "tracerstudy-post-service/modules/revocation/builder"
+	postService "tracerstudy-post-service/modules/post/service"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 	"gorm.io/gorm"
 )
 
@@ -20,38 +46,231 @@ func main() {
 	cfg, cerr := config.NewConfig(".env")
 	checkError(cerr)
 
-	splash(cfg)
+	logger.Init(cfg.Log)
 
-	// http.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir("./public/uploads"))))
-	// log.Fatal(http.ListenAndServe(":8080", nil))
+	splash(cfg)
 
 	dsn, derr := mysql.NewPool(&cfg.MySQL)
 	checkError(derr)
 	// errUtils.ConvertToRestError(derr)
 
-	db, gerr := gormConn.NewMySQLGormDB(dsn)
+	db, gerr := gormConn.NewMySQLGormDB(dsn, &cfg.Log)
 	checkError(gerr)
 	// errUtils.ConvertToRestError(gerr)
 
-	jwtManager := commonJwt.NewJWT(cfg.JWT.JwtSecretKey, cfg.JWT.TokenDuration)
+	if cfg.MySQL.AutoMigrate {
+		checkError(migrate(db))
+	}
+
+	store, serr := storage.NewStorage(*cfg)
+	checkError(serr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jwtManager, jerr := newJWT(ctx, cfg.JWT)
+	checkError(jerr)
+
+	mediaSigner, merr := signer.NewMediaSigner(*cfg)
+	checkError(merr)
+
+	validator := validation.NewValidator()
+	validator.RegisterExistsCheck("post", postRepository.NewPostRepository(db).Exists)
+	validator.RegisterExistsCheck("comment", commentRepository.NewCommentRepository(db).Exists)
+
+	policy, perr := authorization.NewPolicyStore(cfg.Authorization.PolicyFile)
+	checkError(perr)
+	go policy.Watch(ctx, cfg.Authorization.PolicyReloadInterval)
+
+	revocationSvc := revocationBuilder.BuildRevocationService(*cfg, db)
+
+	limiter := ratelimit.NewMemoryStore()
+	go limiter.Start(ctx)
+
+	tlsConfig, certs, tlserr := newServerTLS(ctx, cfg.TLS)
+	checkError(tlserr)
+	var httpTLSConfig *tls.Config
+	if certs != nil {
+		httpTLSConfig = server.NewHTTPTLSConfig(certs)
+	}
+
+	grpcServer := server.NewGrpcServer(*cfg, jwtManager, policy, revocationSvc, limiter, validator, tlsConfig)
+	tracerProvider, terr := tracing.NewTracerProvider(context.Background(), *cfg)
+	checkError(terr)
+
+	tracingInterceptor := interceptor.NewTracingInterceptor()
+	gatewayOpts := []server.DialOption{server.WithUnaryClientInterceptors(tracingInterceptor.UnaryClient())}
+	if certs != nil {
+		gatewayOpts = append(gatewayOpts, server.WithSelfCertificate(certs))
+	}
+	grpcConn := server.InitGRPCConn(fmt.Sprintf("127.0.0.1:%v", cfg.Port.GRPC), false, "", gatewayOpts...)
+	authTLS := cfg.ClientURL.AuthTLS
+	authConn := server.InitGRPCConn(cfg.ClientURL.Auth, authTLS.Enabled, authTLS.CAFile,
+		server.WithServerName(authTLS.ServerName),
+		server.WithClientCertificate(authTLS.CertFile, authTLS.KeyFile),
+		server.WithUnaryClientInterceptors(tracingInterceptor.UnaryClient()),
+	)
 
-	grpcServer := server.NewGrpcServer(cfg.Port.GRPC, jwtManager)
-	grpcConn := server.InitGRPCConn(fmt.Sprintf("127.0.0.1:%v", cfg.Port.GRPC), false, "")
+	registerGrpcHandlers(grpcServer.Server, *cfg, db, grpcConn, authConn, store, mediaSigner)
+	revocationModule.InitGrpc(grpcServer.Server, *cfg, revocationSvc)
+	permissionModule.InitGrpc(grpcServer.Server, *cfg, policy)
 
-	registerGrpcHandlers(grpcServer.Server, *cfg, db, grpcConn)
+	checks := healthChecks(db, store, authConn)
+	servers := []server.Stopper{grpcServer}
 
-	_ = grpcServer.Run()
-	_ = grpcServer.AwaitTermination()
+	if cfg.Grpc.HealthEnabled {
+		grpcHealth := server.NewGrpcHealth(checks, cfg.Grpc.HealthInterval)
+		grpcHealth.Register(grpcServer.Server)
+		go grpcHealth.Start(ctx)
+		servers = append(servers, grpcHealth)
+	}
+
+	if cfg.Grpc.ReflectionEnabled {
+		reflection.Register(grpcServer.Server)
+	}
+
+	if cfg.GC.Enabled {
+		go startImageCollector(ctx, *cfg, db, store, mediaSigner)
+	}
+
+	if cfg.Metrics.Enabled {
+		// plaintext, the metrics port is not exposed outside the cluster
+		metricsServer := server.NewRest(cfg.Port.Metrics, nil)
+		metricsServer.Mux.Handle(cfg.Metrics.Path, metrics.Handler())
+		checkError(metricsServer.Run())
+		servers = append(servers, metricsServer)
+	}
+
+	restServer := createRestServer(*cfg, store, mediaSigner, checks, httpTLSConfig)
+	registerRestHandlers(restServer.Mux, grpcConn)
+	servers = append(servers, restServer)
+
+	if cfg.GrpcWeb.Enabled {
+		grpcWebServer := server.NewGrpcWeb(cfg.Port.GRPCWeb, grpcServer.Server, cfg.GrpcWeb.AllowedOrigins, httpTLSConfig)
+		checkError(grpcWebServer.Run())
+		servers = append(servers, grpcWebServer)
+	}
+
+	checkError(grpcServer.Run())
+	checkError(restServer.Run())
+	_ = server.AwaitTermination(cfg.ShutdownTimeout, servers...)
+
+	// flush the spans of the requests drained above
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer flushCancel()
+	_ = tracerProvider.Shutdown(flushCtx)
 }
 
-func registerGrpcHandlers(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) {
-	postModule.InitGrpc(server, cfg, db, grpcConn)
+// newJWT accepts tokens signed with the keys in JWT_JWKS and, while it is set, with JWT_SECRET_KEY.
+func newJWT(ctx context.Context, cfg config.JWTConfig) (*commonJwt.JWT, error) {
+	opts := []commonJwt.Option{
+		commonJwt.WithIssuer(cfg.Issuer),
+		commonJwt.WithAudience(cfg.Audience),
+		commonJwt.WithClockSkew(cfg.ClockSkew),
+	}
+
+	if cfg.JWKS != "" {
+		// a key set may sign tokens for other services too, they must not be accepted here
+		if cfg.Issuer == "" || cfg.Audience == "" {
+			return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE must be set with JWT_JWKS")
+		}
+		keySet, err := commonJwt.NewKeySet(ctx, cfg.JWKS)
+		if err != nil {
+			return nil, fmt.Errorf("error while load JWKS: %w", err)
+		}
+		go keySet.Start(ctx, cfg.JWKSRefresh)
+		opts = append(opts, commonJwt.WithKeySet(keySet))
+	} else if cfg.JwtSecretKey == "" {
+		return nil, fmt.Errorf("either JWT_JWKS or JWT_SECRET_KEY must be set")
+	}
+
+	return commonJwt.NewJWT(cfg.JwtSecretKey, cfg.TokenDuration, opts...), nil
+}
+
+// newServerTLS returns nil when TLS_ENABLED is off, the gRPC, REST and gRPC-Web servers
+// are then plaintext.
+func newServerTLS(ctx context.Context, cfg config.TLS) (*tls.Config, *server.CertReloader, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	certs, err := server.NewCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while load TLS certificate: %w", err)
+	}
+	go certs.Watch(ctx, cfg.ReloadInterval)
+
+	tlsConfig, err := server.NewServerTLSConfig(cfg, certs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while load TLS client CA: %w", err)
+	}
+
+	return tlsConfig, certs, nil
+}
+
+func registerGrpcHandlers(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn, authConn *grpc.ClientConn, store storage.Storage, mediaSigner *signer.Signer) {
+	postModule.InitGrpc(server, cfg, db, authConn, store)
 	commentModule.InitGrpc(server, cfg, db, grpcConn)
+	mediaModule.InitGrpc(server, cfg, db, grpcConn, store, mediaSigner)
+	auditModule.InitGrpc(server, cfg, db)
+}
+
+func registerRestHandlers(mux *http.ServeMux, grpcConn *grpc.ClientConn) {
+	postModule.InitRest(mux, grpcConn)
+	commentModule.InitRest(mux, grpcConn)
 }
 
-// func createRestServer(port string) *server.Rest {
-// 	return server.NewRest(port)
-// }
+func migrate(db *gorm.DB) error {
+	for _, m := range []func(*gorm.DB) error{
+		postModule.Migrate,
+		mediaModule.Migrate,
+		revocationModule.Migrate,
+		auditModule.Migrate,
+	} {
+		if err := m(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func startImageCollector(ctx context.Context, cfg config.Config, db *gorm.DB, store storage.Storage, mediaSigner *signer.Signer) {
+	postSvc := postService.NewPostService(cfg, postRepository.NewPostRepository(db), auditBuilder.BuildAuditService(cfg, db), nil)
+	mediaSvc := mediaService.NewMediaService(cfg, mediaRepository.NewMediaRepository(db), store, mediaSigner)
+	gc.NewCollector(cfg, store, postSvc, mediaSvc).Start(ctx)
+}
+
+func healthChecks(db *gorm.DB, store storage.Storage, authConn *grpc.ClientConn) map[string]server.HealthCheck {
+	return map[string]server.HealthCheck{
+		"mysql": func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+		"auth": server.ConnectionCheck(authConn),
+		"storage": func(ctx context.Context) error {
+			return storage.CheckWritable(ctx, store)
+		},
+	}
+}
+
+func createRestServer(cfg config.Config, store storage.Storage, mediaSigner *signer.Signer, checks map[string]server.HealthCheck, tlsConfig *tls.Config) *server.Rest {
+	rest := server.NewRest(cfg.Port.REST, tlsConfig)
+
+	rest.Mux.Handle("/healthz", server.LivenessHandler())
+	rest.Mux.Handle("/readyz", server.ReadinessHandler(checks))
+
+	// PublicStoragePath may point to a CDN or bucket URL, only local paths are served here
+	if strings.HasPrefix(cfg.PublicStoragePath, "/") {
+		fileServer := server.NewFileServer(cfg, store, mediaSigner, mediaService.PrivatePrefix)
+		rest.Mux.Handle(cfg.PublicStoragePath, fileServer)
+	}
+
+	return rest
+}
 
 func checkError(err error) {
 	if err != nil {
@@ -62,7 +281,7 @@ func checkError(err error) {
 func splash(cfg *config.Config) {
 	version := "1.0.0"
 	colorReset := "\033[0m"
-	// colorBlue := "\033[34m"
+	colorBlue := "\033[34m"
 	colorCyan := "\033[36m"
 
 	fmt.Printf(`
@@ -75,7 +294,10 @@ func splash(cfg *config.Config) {
                                                                                   / ___/
 	`, version)
 
-	// fmt.Println(colorBlue, fmt.Sprintf(`⇨ REST server started on port :%s`, cfg.Port.REST))
+	fmt.Println(colorBlue, fmt.Sprintf(`⇨ REST server started on port :%s`, cfg.Port.REST))
 	fmt.Println(colorCyan, fmt.Sprintf(`⇨ GRPC post service server started on port :%s`, cfg.Port.GRPC))
+	if cfg.GrpcWeb.Enabled {
+		fmt.Println(colorCyan, fmt.Sprintf(`⇨ GRPC-Web server started on port :%s`, cfg.Port.GRPCWeb))
+	}
 	fmt.Println(colorReset, "")
 }
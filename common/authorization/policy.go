@@ -0,0 +1,223 @@
+package authorization
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed policy.yaml
+var defaultPolicy []byte
+
+// Policy maps gRPC methods to the roles allowed to call them. It denies by default: a
+// method that is neither public nor listed in Methods cannot be called at all.
+type Policy struct {
+	Public  []string            `json:"public" yaml:"public"`
+	Methods map[string][]uint32 `json:"methods" yaml:"methods"`
+}
+
+// Rule returns how method is protected. ok is false when the policy does not mention
+// method, which means it is denied.
+func (p *Policy) Rule(method string) (public bool, roles []uint32, ok bool) {
+	for _, pattern := range []string{method, serviceWildcard(method)} {
+		if slices.Contains(p.Public, pattern) {
+			return true, nil, true
+		}
+		if roles, ok := p.Methods[pattern]; ok {
+			return false, roles, true
+		}
+	}
+
+	return false, nil, false
+}
+
+// Allows reports whether a caller with role may call method, role 0 being anonymous.
+func (p *Policy) Allows(method string, role uint32) bool {
+	public, roles, ok := p.Rule(method)
+	if !ok {
+		return false
+	}
+
+	return public || slices.Contains(roles, role)
+}
+
+// Effective returns the methods out of methods that role may call, sorted.
+func (p *Policy) Effective(role uint32, methods []string) []string {
+	var allowed []string
+	for _, method := range methods {
+		if p.Allows(method, role) {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+
+	return allowed
+}
+
+func (p *Policy) validate() error {
+	for _, method := range p.Public {
+		if err := validateMethod(method); err != nil {
+			return err
+		}
+		if _, ok := p.Methods[method]; ok {
+			return fmt.Errorf("%s is both public and restricted to roles", method)
+		}
+	}
+	for method, roles := range p.Methods {
+		if err := validateMethod(method); err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return fmt.Errorf("%s has no roles, list it as public or remove it", method)
+		}
+	}
+
+	return nil
+}
+
+func validateMethod(method string) error {
+	parts := strings.Split(method, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid method %q, expected /package.Service/Method or /package.Service/*", method)
+	}
+
+	return nil
+}
+
+func serviceWildcard(method string) string {
+	i := strings.LastIndex(method, "/")
+	if i < 0 {
+		return method
+	}
+
+	return method[:i+1] + "*"
+}
+
+// ParsePolicy reads a policy in JSON, when name ends with .json, or YAML.
+func ParsePolicy(name string, raw []byte) (*Policy, error) {
+	var policy Policy
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&policy); err != nil {
+			return nil, fmt.Errorf("invalid policy %s: %w", name, err)
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		if err := dec.Decode(&policy); err != nil {
+			return nil, fmt.Errorf("invalid policy %s: %w", name, err)
+		}
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", name, err)
+	}
+
+	return &policy, nil
+}
+
+// PolicyStore holds the current policy. When it is loaded from a file, Watch reloads it
+// whenever the file changes; a file that fails to parse leaves the previous policy in place.
+type PolicyStore struct {
+	path    string
+	current atomic.Pointer[Policy]
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewPolicyStore loads the policy at path, or the embedded default when path is empty.
+func NewPolicyStore(path string) (*PolicyStore, error) {
+	s := &PolicyStore{path: path}
+
+	if path == "" {
+		policy, err := ParsePolicy("policy.yaml", defaultPolicy)
+		if err != nil {
+			return nil, err
+		}
+		s.current.Store(policy)
+		return s, nil
+	}
+
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *PolicyStore) Policy() *Policy {
+	return s.current.Load()
+}
+
+// Watch checks the file for changes every interval until ctx is done.
+func (s *PolicyStore) Watch(ctx context.Context, interval time.Duration) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "[PolicyStore - Watch] Error while reload policy, keeping the previous one", "path", s.path, "error", err)
+				continue
+			}
+			if changed {
+				slog.InfoContext(ctx, "[PolicyStore - Watch] Policy reloaded", "path", s.path)
+			}
+		}
+	}
+}
+
+func (s *PolicyStore) reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Stat follows symlinks, so a ConfigMap update that swaps the link is seen as a change
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	policy, err := ParsePolicy(s.path, raw)
+	if err != nil {
+		// remember the broken file so it is reported once, not on every tick
+		s.modTime, s.size = info.ModTime(), info.Size()
+		return false, err
+	}
+
+	s.current.Store(policy)
+	s.modTime, s.size = info.ModTime(), info.Size()
+
+	return true, nil
+}
@@ -0,0 +1,268 @@
+package authorization
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const testPolicyYAML = `
+public:
+  - /tracer_study_grpc.PostService/GetAllPosts
+  - /tracer_study_grpc.MediaService/GetMediaById
+methods:
+  /tracer_study_grpc.PostService/CreatePost: [1, 5]
+  /tracer_study_grpc.MediaService/*: [1, 2]
+`
+
+func TestPolicyAllows(t *testing.T) {
+	policy, err := ParsePolicy("policy.yaml", []byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		role   uint32
+		want   bool
+	}{
+		{"public method without token", "/tracer_study_grpc.PostService/GetAllPosts", 0, true},
+		{"public method with a role", "/tracer_study_grpc.PostService/GetAllPosts", 6, true},
+		{"listed role", "/tracer_study_grpc.PostService/CreatePost", RoleAdminProdi, true},
+		{"unlisted role", "/tracer_study_grpc.PostService/CreatePost", RoleAlumni, false},
+		{"restricted method without token", "/tracer_study_grpc.PostService/CreatePost", 0, false},
+		{"service wildcard", "/tracer_study_grpc.MediaService/UploadMedia", RoleAdmin, true},
+		{"service wildcard with an unlisted role", "/tracer_study_grpc.MediaService/UploadMedia", RoleAlumni, false},
+		{"exact public entry wins over the wildcard", "/tracer_study_grpc.MediaService/GetMediaById", 0, true},
+		{"method missing from the policy", "/tracer_study_grpc.PostService/DeletePost", RoleSuperAdmin, false},
+		{"service missing from the policy", "/tracer_study_grpc.AuditService/ListAuditEvents", RoleSuperAdmin, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allows(tt.method, tt.role); got != tt.want {
+				t.Fatalf("Allows(%s, %d) = %v, want %v", tt.method, tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyEffective(t *testing.T) {
+	policy, err := ParsePolicy("policy.yaml", []byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	methods := []string{
+		"/tracer_study_grpc.PostService/CreatePost",
+		"/tracer_study_grpc.MediaService/UploadMedia",
+		"/tracer_study_grpc.PostService/GetAllPosts",
+		"/tracer_study_grpc.PostService/DeletePost",
+	}
+	want := []string{
+		"/tracer_study_grpc.MediaService/UploadMedia",
+		"/tracer_study_grpc.PostService/CreatePost",
+		"/tracer_study_grpc.PostService/GetAllPosts",
+	}
+
+	if got := policy.Effective(RoleSuperAdmin, methods); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Effective() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		raw     string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "policy.yaml",
+			raw:  testPolicyYAML,
+		},
+		{
+			name: "json",
+			file: "policy.json",
+			raw:  `{"public": ["/tracer_study_grpc.PostService/GetAllPosts"], "methods": {"/tracer_study_grpc.PostService/CreatePost": [1]}}`,
+		},
+		{
+			name:    "unknown yaml field",
+			file:    "policy.yaml",
+			raw:     "public: []\nroles: {}\n",
+			wantErr: "roles",
+		},
+		{
+			name:    "unknown json field",
+			file:    "policy.json",
+			raw:     `{"public": [], "roles": {}}`,
+			wantErr: "roles",
+		},
+		{
+			name:    "malformed method",
+			file:    "policy.yaml",
+			raw:     "methods:\n  PostService/CreatePost: [1]\n",
+			wantErr: "invalid method",
+		},
+		{
+			name:    "public and restricted",
+			file:    "policy.yaml",
+			raw:     "public:\n  - /tracer_study_grpc.PostService/CreatePost\nmethods:\n  /tracer_study_grpc.PostService/CreatePost: [1]\n",
+			wantErr: "both public and restricted",
+		},
+		{
+			name:    "method without roles",
+			file:    "policy.yaml",
+			raw:     "methods:\n  /tracer_study_grpc.PostService/CreatePost: []\n",
+			wantErr: "has no roles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy(tt.file, []byte(tt.raw))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParsePolicy() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParsePolicy() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestDefaultPolicyCoversServedMethods guards against denying a new RPC by accident,
+// every method this service serves must be public or restricted to roles.
+func TestDefaultPolicyCoversServedMethods(t *testing.T) {
+	store, err := NewPolicyStore("")
+	if err != nil {
+		t.Fatalf("NewPolicyStore() error = %v", err)
+	}
+	policy := store.Policy()
+
+	// clients of other services, they are not served here
+	external := map[string]bool{
+		pb.AuthService_ServiceDesc.ServiceName: true,
+		pb.UserService_ServiceDesc.ServiceName: true,
+	}
+
+	files := []protoreflect.FileDescriptor{
+		pb.File_post_proto,
+		pb.File_comment_proto,
+		pb.File_media_proto,
+		pb.File_audit_proto,
+		pb.File_permission_proto,
+		pb.File_revocation_proto,
+	}
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			if external[string(service.FullName())] {
+				continue
+			}
+			methods := service.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := "/" + string(service.FullName()) + "/" + string(methods.Get(j).Name())
+				if _, _, ok := policy.Rule(method); !ok {
+					t.Errorf("default policy does not mention %s", method)
+				}
+			}
+		}
+	}
+}
+
+func writePolicy(t *testing.T, path, raw string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPolicyStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	start := time.Now().Add(-time.Hour)
+	createPost := "/tracer_study_grpc.PostService/CreatePost"
+
+	writePolicy(t, path, "methods:\n  "+createPost+": [1]\n", start)
+	store, err := NewPolicyStore(path)
+	if err != nil {
+		t.Fatalf("NewPolicyStore() error = %v", err)
+	}
+	if store.Policy().Allows(createPost, RoleAdmin) {
+		t.Fatal("Admin allowed before the reload")
+	}
+
+	if changed, err := store.reload(); changed || err != nil {
+		t.Fatalf("reload() of an unchanged file = %v, %v, want false, nil", changed, err)
+	}
+
+	writePolicy(t, path, "methods:\n  "+createPost+": [1, 2]\n", start.Add(time.Minute))
+	if changed, err := store.reload(); !changed || err != nil {
+		t.Fatalf("reload() = %v, %v, want true, nil", changed, err)
+	}
+	if !store.Policy().Allows(createPost, RoleAdmin) {
+		t.Fatal("Admin denied after the reload")
+	}
+
+	writePolicy(t, path, "methods:\n  "+createPost+": []\n", start.Add(2*time.Minute))
+	if _, err := store.reload(); err == nil {
+		t.Fatal("reload() of a broken file error = nil")
+	}
+	if !store.Policy().Allows(createPost, RoleAdmin) {
+		t.Fatal("a broken file replaced the previous policy")
+	}
+	// reported once, not on every tick
+	if _, err := store.reload(); err != nil {
+		t.Fatalf("second reload() of the same broken file error = %v", err)
+	}
+
+	writePolicy(t, path, "public:\n  - "+createPost+"\n", start.Add(3*time.Minute))
+	if changed, err := store.reload(); !changed || err != nil {
+		t.Fatalf("reload() of the fixed file = %v, %v, want true, nil", changed, err)
+	}
+	if !store.Policy().Allows(createPost, 0) {
+		t.Fatal("fixed file was not loaded")
+	}
+}
+
+func TestPolicyStoreWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	createPost := "/tracer_study_grpc.PostService/CreatePost"
+
+	writePolicy(t, path, `{"methods": {"`+createPost+`": [1]}}`, time.Now().Add(-time.Hour))
+	store, err := NewPolicyStore(path)
+	if err != nil {
+		t.Fatalf("NewPolicyStore() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.Watch(ctx, 10*time.Millisecond)
+
+	writePolicy(t, path, `{"methods": {"`+createPost+`": [1, 8]}}`, time.Now())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !store.Policy().Allows(createPost, RoleAdminPost) {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not pick up the changed policy")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
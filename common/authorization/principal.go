@@ -0,0 +1,47 @@
+package authorization
+
+import (
+	"context"
+	"tracerstudy-post-service/common/errors"
+	commonJwt "tracerstudy-post-service/common/jwt"
+)
+
+// Principal is the caller of an RPC as asserted by its verified access token.
+type Principal struct {
+	UserId    uint64
+	Cred      string
+	Role      uint32
+	KodeProdi string
+}
+
+type principalKey struct{}
+
+func NewPrincipal(claims *commonJwt.CustomClaims) *Principal {
+	return &Principal{
+		UserId:    claims.UserId,
+		Cred:      claims.Cred,
+		Role:      claims.Role,
+		KodeProdi: claims.KodeProdi,
+	}
+}
+
+// WithPrincipal returns a copy of ctx that carries p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal put in ctx by the auth interceptor, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// RequirePrincipal is PrincipalFromContext for code paths that cannot run anonymously.
+func RequirePrincipal(ctx context.Context) (*Principal, error) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, errors.Unauthenticated(errors.ReasonMissingCredentials, nil, "authentication is required")
+	}
+
+	return p, nil
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joeshaw/envdecode"
@@ -10,17 +13,219 @@ import (
 
 type Config struct {
 	ServiceName       string `env:"SERVICE_NAME,default=tracer-study-grpc"`
+	Log               Log
 	Port              Port
+	HTTP              HTTP
+	Grpc              Grpc
+	TLS               TLS
+	RateLimit         RateLimit
+	Metrics           Metrics
+	Tracing           Tracing
+	GrpcWeb           GrpcWeb
+	ShutdownTimeout   time.Duration `env:"SHUTDOWN_TIMEOUT,default=15s"`
 	MySQL             MySQL
 	StoragePath       string `env:"STORAGE_PATH,default=./uploads/"`
 	PublicStoragePath string `env:"PUBLIC_STORAGE_PATH,default=/uploads/"`
+	Storage           Storage
+	Image             Image
+	GC                GC
+	Media             Media
 	JWT               JWTConfig
+	Revocation        Revocation
+	Authorization     Authorization
 	ClientURL         ClientURL
 }
 
+type Log struct {
+	// Level is one of debug, info, warn or error.
+	Level              string        `env:"LOG_LEVEL,default=info"`
+	SlowQueryThreshold time.Duration `env:"LOG_SLOW_QUERY_THRESHOLD,default=200ms"`
+}
+
 type Port struct {
-	GRPC string `env:"PORT_GRPC,default=8081"`
-	REST string `env:"PORT_REST,default=8080"`
+	GRPC    string `env:"PORT_GRPC,default=8081"`
+	REST    string `env:"PORT_REST,default=8080"`
+	GRPCWeb string `env:"PORT_GRPC_WEB,default=8082"`
+	// Metrics serves the Prometheus endpoint apart from the public REST port, keep it internal.
+	Metrics string `env:"PORT_METRICS,default=9090"`
+}
+
+type Grpc struct {
+	// DefaultTimeout bounds unary RPCs whose caller did not set a deadline, 0 disables it.
+	DefaultTimeout time.Duration `env:"GRPC_DEFAULT_TIMEOUT,default=30s"`
+	// MethodTimeouts overrides DefaultTimeout, e.g. "/tracer_study_grpc.PostService/CreatePost=2m".
+	MethodTimeouts    MethodTimeouts `env:"GRPC_METHOD_TIMEOUTS"`
+	HealthEnabled     bool           `env:"GRPC_HEALTH_ENABLED,default=true"`
+	HealthInterval    time.Duration  `env:"GRPC_HEALTH_INTERVAL,default=10s"`
+	ReflectionEnabled bool           `env:"GRPC_REFLECTION_ENABLED,default=false"`
+}
+
+type TLS struct {
+	// Enabled serves gRPC, REST and gRPC-Web over TLS with the certificate in CertFile and
+	// KeyFile. The metrics port stays plaintext.
+	Enabled  bool   `env:"TLS_ENABLED,default=false"`
+	CertFile string `env:"TLS_CERT_FILE"`
+	KeyFile  string `env:"TLS_KEY_FILE"`
+	// ClientCAFile turns on mutual TLS on the gRPC port, clients must present a certificate signed by one of its CAs.
+	ClientCAFile string `env:"TLS_CLIENT_CA_FILE"`
+	// ClientCertOptional also accepts clients without a certificate, the ones that send one are still verified.
+	ClientCertOptional bool `env:"TLS_CLIENT_CERT_OPTIONAL,default=false"`
+	// ReloadInterval is how often the certificate files are checked for changes, 0 disables reloading.
+	ReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL,default=1m"`
+}
+
+type RateLimit struct {
+	Enabled bool `env:"RATE_LIMIT_ENABLED,default=true"`
+	// Default applies to methods without a limit of their own, empty means unlimited.
+	Default Rate `env:"RATE_LIMIT_DEFAULT"`
+	// Methods holds per-method limits, e.g. "/tracer_study_grpc.CommentService/CreateComment=5/1m".
+	Methods MethodRates `env:"RATE_LIMIT_METHODS,default=/tracer_study_grpc.CommentService/CreateComment=5/1m;/tracer_study_grpc.CommentService/ReplyComment=5/1m;/tracer_study_grpc.PostService/AddVisitor=10/1m"`
+}
+
+type Metrics struct {
+	Enabled bool   `env:"METRICS_ENABLED,default=true"`
+	Path    string `env:"METRICS_PATH,default=/metrics"`
+}
+
+type Tracing struct {
+	// Exporter is one of "none", "stdout" or "otlp".
+	Exporter     string  `env:"TRACING_EXPORTER,default=none"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT,default=localhost:4317"`
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE,default=true"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO,default=1"`
+}
+
+// MethodTimeouts maps a full gRPC method name to its timeout.
+type MethodTimeouts map[string]time.Duration
+
+// Decode parses ";" separated "method=duration" pairs.
+func (m *MethodTimeouts) Decode(value string) error {
+	timeouts := make(MethodTimeouts)
+	for _, pair := range strings.Split(value, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		method, duration, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid method timeout %q, expected method=duration", pair)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return fmt.Errorf("invalid method timeout %q: %v", pair, err)
+		}
+		timeouts[strings.TrimSpace(method)] = timeout
+	}
+
+	*m = timeouts
+	return nil
+}
+
+// Rate allows Requests calls per Period, in bursts of up to Requests calls.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Decode parses "requests/period", e.g. "5/1m".
+func (r *Rate) Decode(value string) error {
+	requests, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return fmt.Errorf("invalid rate %q, expected requests/period", value)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid rate %q, requests must be a positive number", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid rate %q, period must be a positive duration", value)
+	}
+
+	*r = Rate{Requests: n, Period: d}
+	return nil
+}
+
+// MethodRates maps a full gRPC method name to its rate.
+type MethodRates map[string]Rate
+
+// Decode parses ";" separated "method=requests/period" pairs.
+func (m *MethodRates) Decode(value string) error {
+	rates := make(MethodRates)
+	for _, pair := range strings.Split(value, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		method, rate, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid method rate %q, expected method=requests/period", pair)
+		}
+		var r Rate
+		if err := r.Decode(rate); err != nil {
+			return err
+		}
+		rates[strings.TrimSpace(method)] = r
+	}
+
+	*m = rates
+	return nil
+}
+
+type GrpcWeb struct {
+	Enabled bool `env:"GRPC_WEB_ENABLED,default=false"`
+	// AllowedOrigins is a ";" separated list of CORS origins, "*" allows every origin.
+	AllowedOrigins []string `env:"GRPC_WEB_ALLOWED_ORIGINS"`
+}
+
+type Storage struct {
+	Driver string `env:"STORAGE_DRIVER,default=local"`
+	S3     S3
+}
+
+type S3 struct {
+	Endpoint  string `env:"S3_ENDPOINT"`
+	Region    string `env:"S3_REGION"`
+	Bucket    string `env:"S3_BUCKET"`
+	AccessKey string `env:"S3_ACCESS_KEY"`
+	SecretKey string `env:"S3_SECRET_KEY"`
+	UseSSL    bool   `env:"S3_USE_SSL,default=true"`
+	Prefix    string `env:"S3_PREFIX"`
+}
+
+type Image struct {
+	RetainCopyright bool `env:"IMAGE_RETAIN_COPYRIGHT,default=false"`
+	// MaxPixels bounds width*height of uploads, checked before they are decoded. A small
+	// file can declare dimensions that take gigabytes once decoded. 0 disables the check.
+	MaxPixels int64 `env:"IMAGE_MAX_PIXELS,default=40000000"`
+}
+
+type GC struct {
+	Enabled     bool          `env:"GC_ENABLED,default=false"`
+	Interval    time.Duration `env:"GC_INTERVAL,default=24h"`
+	GracePeriod time.Duration `env:"GC_GRACE_PERIOD,default=24h"`
+	// DeletedRetention keeps the images of soft deleted posts so they can be restored,
+	// afterwards they are collected like any orphan. 0 keeps them forever.
+	DeletedRetention time.Duration `env:"GC_DELETED_RETENTION,default=720h"`
+	DryRun           bool          `env:"GC_DRY_RUN,default=false"`
+}
+
+type Media struct {
+	// SigningKey signs private media URLs, JWT_SECRET_KEY is used when it is empty.
+	// It is required when JWT_JWKS is set.
+	SigningKey   string        `env:"MEDIA_SIGNING_KEY"`
+	SignedURLTTL time.Duration `env:"MEDIA_SIGNED_URL_TTL,default=15m"`
+}
+
+type HTTP struct {
+	// CacheMaxAge is sent as Cache-Control max-age for public uploads.
+	CacheMaxAge time.Duration `env:"HTTP_CACHE_MAX_AGE,default=168h"`
+	// TrustedProxies is the number of reverse proxies in front of the REST gateway, whose
+	// X-Forwarded-For entries are trusted when resolving the client address.
+	TrustedProxies int `env:"HTTP_TRUSTED_PROXIES,default=0"`
 }
 
 type MySQL struct {
@@ -29,15 +234,51 @@ type MySQL struct {
 	User     string `env:"MYSQL_USER,default=root"`
 	Password string `env:"MYSQL_PASSWORD,default=skrmk372"`
 	Name     string `env:"MYSQL_NAME,default=new_tracer"`
+	// AutoMigrate creates the missing tables, columns and indexes on startup.
+	AutoMigrate bool `env:"MYSQL_AUTO_MIGRATE,default=true"`
 }
 
 type JWTConfig struct {
+	// JwtSecretKey verifies HS256 tokens, leave it empty to accept only JWKS signed tokens.
 	JwtSecretKey  string        `env:"JWT_SECRET_KEY"`
 	TokenDuration time.Duration `env:"JWT_DURATION,default=30m"`
+	// JWKS is a file path or an http(s) URL of the auth service's public keys.
+	JWKS        string        `env:"JWT_JWKS"`
+	JWKSRefresh time.Duration `env:"JWT_JWKS_REFRESH_INTERVAL,default=10m"`
+	// Issuer and Audience are required with JWKS and checked when set.
+	Issuer    string        `env:"JWT_ISSUER"`
+	Audience  string        `env:"JWT_AUDIENCE"`
+	ClockSkew time.Duration `env:"JWT_CLOCK_SKEW,default=30s"`
+}
+
+type Authorization struct {
+	// PolicyFile is a YAML or JSON permission policy, the built-in one is used when empty.
+	PolicyFile           string        `env:"AUTHZ_POLICY_FILE"`
+	PolicyReloadInterval time.Duration `env:"AUTHZ_POLICY_RELOAD_INTERVAL,default=10s"`
+}
+
+type Revocation struct {
+	// CacheTTL is how long the revocation list is served from memory, and so how long a
+	// revocation made on another replica may take to apply here.
+	CacheTTL time.Duration `env:"REVOCATION_CACHE_TTL,default=30s"`
 }
 
 type ClientURL struct {
 	Auth string `env:"CLIENT_URL_AUTH"`
+	// AuthProfileCacheTTL is how long user profiles fetched from the auth service are reused.
+	AuthProfileCacheTTL time.Duration `env:"CLIENT_AUTH_PROFILE_CACHE_TTL,default=5m"`
+	AuthTLS             AuthTLS
+}
+
+type AuthTLS struct {
+	Enabled bool `env:"CLIENT_AUTH_TLS_ENABLED,default=false"`
+	// CAFile verifies the auth service certificate, the system roots are used when it is empty.
+	CAFile string `env:"CLIENT_AUTH_TLS_CA_FILE"`
+	// ServerName is sent as SNI and checked against the certificate instead of the host of CLIENT_URL_AUTH.
+	ServerName string `env:"CLIENT_AUTH_TLS_SERVER_NAME"`
+	// CertFile and KeyFile are presented to the auth service when it requires mutual TLS.
+	CertFile string `env:"CLIENT_AUTH_TLS_CERT_FILE"`
+	KeyFile  string `env:"CLIENT_AUTH_TLS_KEY_FILE"`
 }
 
 func NewConfig(env string) (*Config, error) {
@@ -1,82 +1,191 @@
 package errors
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"strconv"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+// Domain is sent in the ErrorInfo of every error converted by ToStatus.
+const Domain = "tracerstudy-post-service"
+
+// Kinds of failures. Wrapped errors keep their kind, so errors.Is(err, ErrNotFound)
+// works at any layer.
 var (
-	ErrRecordNotFound      = NewError(codes.NotFound, "record not found")
-	ErrInternalServerError = NewError(codes.Internal, "internal server error")
-	ErrBadRequest          = NewError(codes.InvalidArgument, "bad request")
+	ErrNotFound              = errors.New("not found")
+	ErrConflict              = errors.New("conflict")
+	ErrValidation            = errors.New("validation failed")
+	ErrUnauthenticated       = errors.New("unauthenticated")
+	ErrForbidden             = errors.New("forbidden")
+	ErrDependencyUnavailable = errors.New("dependency unavailable")
+	ErrResourceExhausted     = errors.New("resource exhausted")
 )
 
+var kindToCode = map[error]codes.Code{
+	ErrNotFound:              codes.NotFound,
+	ErrConflict:              codes.AlreadyExists,
+	ErrValidation:            codes.InvalidArgument,
+	ErrUnauthenticated:       codes.Unauthenticated,
+	ErrForbidden:             codes.PermissionDenied,
+	ErrDependencyUnavailable: codes.Unavailable,
+	ErrResourceExhausted:     codes.ResourceExhausted,
+}
+
+// Error is a failure of a known kind. Message is safe to return to clients,
+// Cause is only logged.
 type Error struct {
-	Code    codes.Code `json:"code"`
-	Message string     `json:"message"`
+	Kind     error
+	Reason   string
+	Message  string
+	Cause    error
+	Metadata map[string]string
+	// RetryAfter is sent as google.rpc.RetryInfo when it is set.
+	RetryAfter time.Duration
 }
 
-func NewError(code codes.Code, message string) *Error {
+func newError(kind error, reason string, cause error, format string, args ...any) *Error {
 	return &Error{
-		Code:    code,
-		Message: message,
+		Kind:    kind,
+		Reason:  reason,
+		Message: fmt.Sprintf(format, args...),
+		Cause:   cause,
+	}
+}
+
+func NotFound(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrNotFound, reason, cause, format, args...)
+}
+
+func Conflict(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrConflict, reason, cause, format, args...)
+}
+
+func Validation(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrValidation, reason, cause, format, args...)
+}
+
+func Unauthenticated(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrUnauthenticated, reason, cause, format, args...)
+}
+
+func Forbidden(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrForbidden, reason, cause, format, args...)
+}
+
+func Unavailable(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrDependencyUnavailable, reason, cause, format, args...)
+}
+
+func ResourceExhausted(reason string, cause error, format string, args ...any) *Error {
+	return newError(ErrResourceExhausted, reason, cause, format, args...)
+}
+
+// WithMetadata adds a key to the ErrorInfo metadata sent to the client.
+func (e *Error) WithMetadata(key, value string) *Error {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
 	}
+	e.Metadata[key] = value
+
+	return e
+}
+
+// WithRetryAfter tells the client when to try again, in RetryInfo and in the
+// "retry_after" metadata as whole seconds.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	e.RetryAfter = d
+
+	return e.WithMetadata("retry_after", strconv.FormatInt(RetryAfterSeconds(d), 10))
 }
 
-func (err *Error) Error() error {
-	return fmt.Errorf("%d:%s", err.Code, err.Message)
+// RetryAfterSeconds rounds d up to whole seconds, as used by Retry-After headers.
+func RetryAfterSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
 }
 
-func ParseError(err error) *Error {
-	var statusCode int32
-	if st, ok := status.FromError(err); ok {
-		// statusCode := st.Code()
-		statusCode = st.Proto().Code
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
 	}
 
-	// log.Println(err.Error())
-	// fmt.Println("Status code:", int(statusCode))
-
-	var strToCode = map[int]codes.Code{
-		0:  codes.OK,
-		1:  codes.Canceled,
-		2:  codes.Unknown,
-		3:  codes.InvalidArgument,
-		4:  codes.DeadlineExceeded,
-		5:  codes.NotFound,
-		6:  codes.AlreadyExists,
-		7:  codes.PermissionDenied,
-		8:  codes.ResourceExhausted,
-		9:  codes.FailedPrecondition,
-		10: codes.Aborted,
-		11: codes.OutOfRange,
-		12: codes.Unimplemented,
-		13: codes.Internal,
-		14: codes.Unavailable,
-		15: codes.DataLoss,
-		16: codes.Unauthenticated,
+	return e.Message + ": " + e.Cause.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Kind}
 	}
 
+	return []error{e.Kind, e.Cause}
+}
+
+// Is reports whether err has the given kind or cause, see errors.Is.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, see errors.As.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// ToStatus converts err into a gRPC status error. It is meant to be called once, at the
+// server boundary. Errors that already are a status pass through unchanged, errors of
+// unknown kind become Internal without exposing their message.
+func ToStatus(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	// split := strings.Split(err.Error(), ":")
+	var e *Error
+	if errors.As(err, &e) {
+		code, ok := kindToCode[e.Kind]
+		if !ok {
+			code = codes.Internal
+		}
+		st := status.New(code, e.Message)
+		if e.RetryAfter > 0 {
+			if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)}); err == nil {
+				st = detailed
+			}
+		}
+		return withErrorInfo(st, e.Reason, e.Metadata)
+	}
 
-	parts := strings.Split(err.Error(), "desc = ")
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
 
-	errlen := len(parts)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return withErrorInfo(status.New(codes.DeadlineExceeded, "request deadline exceeded"), ReasonDeadlineExceeded, nil)
+	}
+
+	return withErrorInfo(status.New(codes.Internal, "internal server error"), ReasonInternal, nil)
+}
 
-	outputmsg := ""
+func withErrorInfo(st *status.Status, reason string, metadata map[string]string) error {
+	if reason == "" {
+		return st.Err()
+	}
 
-	if errlen > 1 {
-		outputmsg = parts[errlen-1]
-	} else {
-		outputmsg = "No Error Desc"
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
 	}
 
-	return NewError(strToCode[int(statusCode)], outputmsg)
+	return detailed.Err()
 }
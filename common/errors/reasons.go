@@ -0,0 +1,34 @@
+package errors
+
+// Reason codes sent in google.rpc.ErrorInfo. They are part of the API, clients may
+// switch on them, so existing values must never change.
+const (
+	ReasonInternal         = "INTERNAL"
+	ReasonDeadlineExceeded = "DEADLINE_EXCEEDED"
+
+	ReasonPostNotFound          = "POST_NOT_FOUND"
+	ReasonPostAlreadyExists     = "POST_ALREADY_EXISTS"
+	ReasonCommentNotFound       = "COMMENT_NOT_FOUND"
+	ReasonParentCommentNotFound = "PARENT_COMMENT_NOT_FOUND"
+	ReasonMediaNotFound         = "MEDIA_NOT_FOUND"
+	ReasonRevocationNotFound    = "REVOCATION_NOT_FOUND"
+
+	ReasonInvalidRequest    = "INVALID_REQUEST"
+	ReasonUnsupportedImage  = "UNSUPPORTED_IMAGE"
+	ReasonInvalidVisibility = "INVALID_VISIBILITY"
+	ReasonInvalidTimestamp  = "INVALID_TIMESTAMP"
+	ReasonMissingUser       = "MISSING_USER"
+
+	ReasonMissingCredentials  = "MISSING_CREDENTIALS"
+	ReasonInvalidToken        = "INVALID_TOKEN"
+	ReasonPermissionDenied    = "PERMISSION_DENIED"
+	ReasonTokenRevoked        = "TOKEN_REVOKED"
+	ReasonNotPostOwner        = "NOT_POST_OWNER"
+	ReasonMissingStudyProgram = "MISSING_STUDY_PROGRAM"
+
+	ReasonDatabaseUnavailable    = "DATABASE_UNAVAILABLE"
+	ReasonStorageUnavailable     = "STORAGE_UNAVAILABLE"
+	ReasonAuthServiceUnavailable = "AUTH_SERVICE_UNAVAILABLE"
+
+	ReasonRateLimited = "RATE_LIMITED"
+)
@@ -0,0 +1,122 @@
+package gc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/storage"
+)
+
+// ReferenceSource reports every public image path still referenced by a record.
+// Paths that do not start with PublicStoragePath are ignored.
+type ReferenceSource interface {
+	ImageReferences(ctx context.Context) ([]string, error)
+}
+
+type Report struct {
+	Scanned    int
+	Referenced int
+	// Recent holds unreferenced objects younger than the grace period, they may belong to an upload in flight.
+	Recent  []*storage.Object
+	Orphans []*storage.Object
+	Deleted int
+	Failed  int
+	DryRun  bool
+}
+
+// Collector removes stored files that are no longer referenced by any source.
+type Collector struct {
+	cfg         config.Config
+	storage     storage.Storage
+	sources     []ReferenceSource
+	gracePeriod time.Duration
+}
+
+func NewCollector(cfg config.Config, store storage.Storage, sources ...ReferenceSource) *Collector {
+	return &Collector{
+		cfg:         cfg,
+		storage:     store,
+		sources:     sources,
+		gracePeriod: cfg.GC.GracePeriod,
+	}
+}
+
+// WithGracePeriod overrides the GC_GRACE_PERIOD setting.
+func (c *Collector) WithGracePeriod(gracePeriod time.Duration) *Collector {
+	c.gracePeriod = gracePeriod
+	return c
+}
+
+func (c *Collector) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	referenced := make(map[string]struct{})
+	for _, source := range c.sources {
+		paths, err := source.ImageReferences(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "[Collector - Run] Error while get image references", "error", err)
+			return nil, err
+		}
+		for _, path := range paths {
+			if key, ok := storage.KeyFromPublicPath(c.cfg.PublicStoragePath, path); ok {
+				referenced[key] = struct{}{}
+			}
+		}
+	}
+
+	objects, err := c.storage.List(ctx, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "[Collector - Run] Error while list stored objects", "error", err)
+		return nil, err
+	}
+
+	report := &Report{
+		Scanned: len(objects),
+		DryRun:  dryRun,
+	}
+	cutoff := time.Now().Add(-c.gracePeriod)
+
+	for _, obj := range objects {
+		if _, ok := referenced[obj.Key]; ok {
+			report.Referenced++
+			continue
+		}
+		if obj.ModTime.After(cutoff) {
+			report.Recent = append(report.Recent, obj)
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, obj)
+		if dryRun {
+			continue
+		}
+
+		if err := c.storage.Delete(ctx, obj.Key); err != nil {
+			slog.ErrorContext(ctx, "[Collector - Run] Error while delete orphan", "key", obj.Key, "error", err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+	}
+
+	return report, nil
+}
+
+// Start runs the collector every GC_INTERVAL until ctx is done.
+func (c *Collector) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.GC.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := c.Run(ctx, c.cfg.GC.DryRun)
+			if err != nil {
+				continue
+			}
+			slog.InfoContext(ctx, "[Collector - Start] Collection finished",
+				"scanned", report.Scanned, "orphans", len(report.Orphans), "deleted", report.Deleted, "failed", report.Failed, "dry_run", report.DryRun)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package gc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/storage"
+)
+
+const testPublicPath = "/uploads/"
+
+type staticSource []string
+
+func (s staticSource) ImageReferences(ctx context.Context) ([]string, error) {
+	return s, nil
+}
+
+type failingSource struct{}
+
+func (failingSource) ImageReferences(ctx context.Context) ([]string, error) {
+	return nil, errors.New("database is unavailable")
+}
+
+// agedStorage reports the modification times set in ages, as if the objects were written that long ago.
+type agedStorage struct {
+	*storage.MemoryStorage
+	ages map[string]time.Duration
+}
+
+func (s *agedStorage) List(ctx context.Context, prefix string) ([]*storage.Object, error) {
+	objects, err := s.MemoryStorage.List(ctx, prefix)
+	for _, obj := range objects {
+		obj.ModTime = obj.ModTime.Add(-s.ages[obj.Key])
+	}
+
+	return objects, err
+}
+
+func newTestStorage(t *testing.T, ages map[string]time.Duration) *agedStorage {
+	t.Helper()
+
+	store := &agedStorage{MemoryStorage: storage.NewMemoryStorage(), ages: ages}
+	for key := range ages {
+		if err := store.Put(context.Background(), key, []byte(key)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	return store
+}
+
+func objectKeys(objects []*storage.Object) string {
+	keys := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ",")
+}
+
+func remainingKeys(t *testing.T, store storage.Storage) string {
+	t.Helper()
+
+	objects, err := store.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	return objectKeys(objects)
+}
+
+func TestCollectorRun(t *testing.T) {
+	ages := map[string]time.Duration{
+		"post.png":        48 * time.Hour,
+		"post_thumb.webp": 48 * time.Hour,
+		"media.pdf":       48 * time.Hour,
+		"orphan.png":      48 * time.Hour,
+		"old_orphan.jpg":  30 * 24 * time.Hour,
+		"uploading.png":   time.Minute,
+	}
+	sources := []ReferenceSource{
+		staticSource{testPublicPath + "post.png", testPublicPath + "post_thumb.webp", "https://cdn.example.com/orphan.png", ""},
+		staticSource{testPublicPath + "media.pdf"},
+	}
+
+	tests := []struct {
+		name          string
+		dryRun        bool
+		grace         time.Duration
+		wantRecent    string
+		wantOrphans   string
+		wantDeleted   int
+		wantRemaining string
+	}{
+		{
+			name:          "orphans are deleted",
+			grace:         time.Hour,
+			wantRecent:    "uploading.png",
+			wantOrphans:   "old_orphan.jpg,orphan.png",
+			wantDeleted:   2,
+			wantRemaining: "media.pdf,post.png,post_thumb.webp,uploading.png",
+		},
+		{
+			name:          "dry run deletes nothing",
+			dryRun:        true,
+			grace:         time.Hour,
+			wantRecent:    "uploading.png",
+			wantOrphans:   "old_orphan.jpg,orphan.png",
+			wantRemaining: "media.pdf,old_orphan.jpg,orphan.png,post.png,post_thumb.webp,uploading.png",
+		},
+		{
+			name:          "files younger than the grace period are kept",
+			grace:         72 * time.Hour,
+			wantRecent:    "orphan.png,uploading.png",
+			wantOrphans:   "old_orphan.jpg",
+			wantDeleted:   1,
+			wantRemaining: "media.pdf,orphan.png,post.png,post_thumb.webp,uploading.png",
+		},
+		{
+			name:          "no grace period",
+			grace:         0,
+			wantOrphans:   "old_orphan.jpg,orphan.png,uploading.png",
+			wantDeleted:   3,
+			wantRemaining: "media.pdf,post.png,post_thumb.webp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStorage(t, ages)
+			collector := NewCollector(config.Config{PublicStoragePath: testPublicPath}, store, sources...).WithGracePeriod(tt.grace)
+
+			report, err := collector.Run(context.Background(), tt.dryRun)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if report.Scanned != len(ages) || report.Referenced != 3 {
+				t.Errorf("Run() scanned %d, referenced %d, want %d, 3", report.Scanned, report.Referenced, len(ages))
+			}
+			if got := objectKeys(report.Recent); got != tt.wantRecent {
+				t.Errorf("Run() recent = %q, want %q", got, tt.wantRecent)
+			}
+			if got := objectKeys(report.Orphans); got != tt.wantOrphans {
+				t.Errorf("Run() orphans = %q, want %q", got, tt.wantOrphans)
+			}
+			if report.Deleted != tt.wantDeleted || report.DryRun != tt.dryRun {
+				t.Errorf("Run() deleted %d, dry run %v, want %d, %v", report.Deleted, report.DryRun, tt.wantDeleted, tt.dryRun)
+			}
+			if got := remainingKeys(t, store); got != tt.wantRemaining {
+				t.Errorf("remaining = %q, want %q", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestCollectorRunFailingSource(t *testing.T) {
+	ages := map[string]time.Duration{
+		"post.png":   48 * time.Hour,
+		"orphan.png": 48 * time.Hour,
+	}
+	store := newTestStorage(t, ages)
+
+	// the failing source may hold the only reference to a file, so nothing can be deleted
+	collector := NewCollector(config.Config{PublicStoragePath: testPublicPath}, store,
+		staticSource{testPublicPath + "post.png"}, failingSource{}).WithGracePeriod(0)
+
+	report, err := collector.Run(context.Background(), false)
+	if err == nil {
+		t.Fatalf("Run() = %+v, want an error", report)
+	}
+	if got := remainingKeys(t, store); got != "orphan.png,post.png" {
+		t.Fatalf("remaining = %q, want every file", got)
+	}
+}
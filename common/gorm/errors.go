@@ -0,0 +1,30 @@
+package gorm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	commonErrors "tracerstudy-post-service/common/errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DatabaseError classifies a gorm error the repository did not handle itself.
+// Connection failures become dependency unavailable, anything else is returned as is.
+func DatabaseError(err error) error {
+	if isConnectionError(err) {
+		return commonErrors.Unavailable(commonErrors.ReasonDatabaseUnavailable, err, "database is unavailable")
+	}
+
+	return err
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.As(err, &netErr)
+}
@@ -1,15 +1,26 @@
 package gorm
 
 import (
+	"log/slog"
+	"tracerstudy-post-service/common/config"
+
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // NewMySQLGormDB builds a connection of gorm to MySQL.
-func NewMySQLGormDB(dsn string) (*gorm.DB, error) {
+func NewMySQLGormDB(dsn string, cfg *config.Log) (*gorm.DB, error) {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         NewLogger(slog.Default(), cfg.SlowQueryThreshold),
+		TranslateError: true,
 	})
-	return db, err
+	if err != nil {
+		return nil, err
+	}
+
+	if err := RegisterMetrics(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
 }
\ No newline at end of file
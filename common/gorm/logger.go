@@ -0,0 +1,59 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slogLogger writes gorm logs through slog. Every statement is logged at debug level,
+// slow statements at warn and failed ones at error; record not found is not a failure.
+type slogLogger struct {
+	log           *slog.Logger
+	slowThreshold time.Duration
+}
+
+func NewLogger(log *slog.Logger, slowThreshold time.Duration) logger.Interface {
+	return &slogLogger{
+		log:           log,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode is a no-op, the level is taken from the slog handler.
+func (l *slogLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.log.InfoContext(ctx, "[Gorm] "+fmt.Sprintf(msg, args...))
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.log.WarnContext(ctx, "[Gorm] "+fmt.Sprintf(msg, args...))
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.log.ErrorContext(ctx, "[Gorm] "+fmt.Sprintf(msg, args...))
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.log.ErrorContext(ctx, "[Gorm] Query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds())/1000)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		sql, rows := fc()
+		l.log.WarnContext(ctx, "[Gorm] Slow query", "sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds())/1000)
+	case l.log.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		l.log.DebugContext(ctx, "[Gorm] Query", "sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds())/1000)
+	}
+}
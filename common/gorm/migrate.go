@@ -0,0 +1,48 @@
+package gorm
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Migrate creates the missing tables of models and adds their missing columns and
+// indexes. Existing columns are never altered or dropped, the tables are shared with
+// the other tracer study services.
+func Migrate(db *gorm.DB, models ...any) error {
+	migrator := db.Migrator()
+
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			if err := migrator.CreateTable(model); err != nil {
+				return fmt.Errorf("create table of %T: %w", model, err)
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("parse %T: %w", model, err)
+		}
+
+		for _, column := range stmt.Schema.DBNames {
+			if migrator.HasColumn(model, column) {
+				continue
+			}
+			if err := migrator.AddColumn(model, column); err != nil {
+				return fmt.Errorf("add column %s.%s: %w", stmt.Schema.Table, column, err)
+			}
+		}
+
+		for name := range stmt.Schema.ParseIndexes() {
+			if migrator.HasIndex(model, name) {
+				continue
+			}
+			if err := migrator.CreateIndex(model, name); err != nil {
+				return fmt.Errorf("create index %s.%s: %w", stmt.Schema.Table, name, err)
+			}
+		}
+	}
+
+	return nil
+}
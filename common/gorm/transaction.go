@@ -0,0 +1,42 @@
+package gorm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Transaction runs fn in a database transaction, which is committed when fn returns nil
+// and rolled back otherwise. Repositories called with the ctx given to fn take part in it
+// through Conn.
+func Transaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	var fnErr error
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fnErr = fn(context.WithValue(ctx, txKey{}, tx))
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		// beginning or committing the transaction failed
+		return DatabaseError(err)
+	}
+
+	return nil
+}
+
+// Conn returns the transaction started by Transaction for ctx, or db outside of one.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+
+	return db
+}
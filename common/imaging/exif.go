@@ -0,0 +1,273 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagArtist      = 0x013B
+	tagCopyright   = 0x8298
+
+	typeASCII = 2
+	typeShort = 3
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// Metadata holds the EXIF tags this service cares about, everything else is discarded.
+type Metadata struct {
+	Orientation int
+	Artist      string
+	Copyright   string
+}
+
+// ReadMetadata extracts the EXIF block of a JPEG, PNG or WebP file and parses IFD0.
+// An image without EXIF data returns a zero Metadata with orientation 1.
+func ReadMetadata(buf []byte) (*Metadata, error) {
+	meta := &Metadata{Orientation: 1}
+
+	tiff, err := findExif(buf)
+	if err != nil {
+		return meta, err
+	}
+	if tiff == nil {
+		return meta, nil
+	}
+
+	if err := parseTiff(tiff, meta); err != nil {
+		return meta, err
+	}
+
+	return meta, nil
+}
+
+// findExif returns the TIFF block of buf, nil when it has none. A segment or chunk that
+// runs past the end of buf is an error.
+func findExif(buf []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(buf, []byte{0xFF, 0xD8}):
+		return findJpegExif(buf)
+	case bytes.HasPrefix(buf, []byte("\x89PNG\r\n\x1a\n")):
+		return findChunk(buf[8:], "eXIf", binary.BigEndian, 12)
+	case len(buf) > 12 && string(buf[0:4]) == "RIFF" && string(buf[8:12]) == "WEBP":
+		tiff, err := findChunk(buf[12:], "EXIF", binary.LittleEndian, 8)
+		return bytes.TrimPrefix(tiff, exifHeader), err
+	}
+
+	return nil, nil
+}
+
+func findJpegExif(buf []byte) ([]byte, error) {
+	i := 2
+	for i+4 <= len(buf) {
+		if buf[i] != 0xFF {
+			return nil, nil
+		}
+		marker := buf[i+1]
+		// start of scan, no more metadata segments follow
+		if marker == 0xDA {
+			return nil, nil
+		}
+		length := int(binary.BigEndian.Uint16(buf[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(buf) {
+			return nil, errors.New("exif: truncated jpeg segment")
+		}
+		segment := buf[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment[len(exifHeader):], nil
+		}
+		i = end
+	}
+
+	return nil, nil
+}
+
+// findChunk walks PNG (length, type, data, crc) or RIFF (type, length, data) chunks.
+func findChunk(buf []byte, name string, order binary.ByteOrder, overhead int) ([]byte, error) {
+	i := 0
+	for i+8 <= len(buf) {
+		var length int
+		var chunkType string
+		var dataStart int
+		if order == binary.BigEndian {
+			length = int(order.Uint32(buf[i:]))
+			chunkType = string(buf[i+4 : i+8])
+		} else {
+			chunkType = string(buf[i : i+4])
+			length = int(order.Uint32(buf[i+4:]))
+		}
+		dataStart = i + 8
+		if length < 0 || dataStart+length > len(buf) {
+			return nil, errors.New("exif: truncated " + chunkType + " chunk")
+		}
+		if chunkType == name {
+			return buf[dataStart : dataStart+length], nil
+		}
+		i += length + overhead
+		// RIFF chunks are padded to an even size
+		if order == binary.LittleEndian && length%2 == 1 {
+			i++
+		}
+	}
+
+	return nil, nil
+}
+
+func parseTiff(tiff []byte, meta *Metadata) error {
+	if len(tiff) < 8 {
+		return errors.New("exif: tiff header too short")
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return errors.New("exif: invalid byte order")
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return errors.New("exif: invalid ifd offset")
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return errors.New("exif: truncated ifd")
+		}
+		tag := order.Uint16(tiff[entry:])
+		typ := order.Uint16(tiff[entry+2:])
+		cnt := int(order.Uint32(tiff[entry+4:]))
+
+		switch {
+		case tag == tagOrientation && typ == typeShort:
+			meta.Orientation = int(order.Uint16(tiff[entry+8:]))
+		case (tag == tagArtist || tag == tagCopyright) && typ == typeASCII:
+			value := tiff[entry+8 : entry+12]
+			if cnt > 4 {
+				start := int(order.Uint32(tiff[entry+8:]))
+				if start+cnt > len(tiff) {
+					continue
+				}
+				value = tiff[start : start+cnt]
+			} else {
+				value = value[:cnt]
+			}
+			str := string(bytes.TrimRight(value, "\x00"))
+			if tag == tagArtist {
+				meta.Artist = str
+			} else {
+				meta.Copyright = str
+			}
+		}
+	}
+
+	if meta.Orientation < 1 || meta.Orientation > 8 {
+		meta.Orientation = 1
+	}
+
+	return nil
+}
+
+// BuildTiff encodes a minimal little endian TIFF block carrying only the artist and copyright tags.
+// It returns nil when both tags are empty.
+func BuildTiff(artist, copyright string) []byte {
+	type asciiTag struct {
+		tag   uint16
+		value []byte
+	}
+
+	var tags []asciiTag
+	if artist != "" {
+		tags = append(tags, asciiTag{tagArtist, append([]byte(artist), 0)})
+	}
+	if copyright != "" {
+		tags = append(tags, asciiTag{tagCopyright, append([]byte(copyright), 0)})
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	order := binary.LittleEndian
+	ifdSize := 2 + len(tags)*12 + 4
+	dataOffset := 8 + ifdSize
+
+	var ifd, data bytes.Buffer
+	_ = binary.Write(&ifd, order, uint16(len(tags)))
+	for _, t := range tags {
+		entry := make([]byte, 12)
+		order.PutUint16(entry[0:], t.tag)
+		order.PutUint16(entry[2:], typeASCII)
+		order.PutUint32(entry[4:], uint32(len(t.value)))
+		if len(t.value) <= 4 {
+			copy(entry[8:], t.value)
+		} else {
+			order.PutUint32(entry[8:], uint32(dataOffset+data.Len()))
+			data.Write(t.value)
+		}
+		ifd.Write(entry)
+	}
+	// no next ifd
+	_ = binary.Write(&ifd, order, uint32(0))
+
+	var out bytes.Buffer
+	out.WriteString("II")
+	_ = binary.Write(&out, order, uint16(42))
+	_ = binary.Write(&out, order, uint32(8))
+	out.Write(ifd.Bytes())
+	out.Write(data.Bytes())
+
+	return out.Bytes()
+}
+
+// EmbedTiff inserts a TIFF block built by BuildTiff into an encoded JPEG or PNG file.
+// Other formats are returned unchanged.
+func EmbedTiff(buf []byte, format string, tiff []byte) []byte {
+	if len(tiff) == 0 {
+		return buf
+	}
+
+	switch format {
+	case "jpeg":
+		if !bytes.HasPrefix(buf, []byte{0xFF, 0xD8}) {
+			return buf
+		}
+		payload := append(append([]byte{}, exifHeader...), tiff...)
+		segment := []byte{0xFF, 0xE1, 0, 0}
+		binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+		segment = append(segment, payload...)
+
+		out := make([]byte, 0, len(buf)+len(segment))
+		out = append(out, buf[:2]...)
+		out = append(out, segment...)
+		return append(out, buf[2:]...)
+	case "png":
+		// signature (8 bytes) followed by the IHDR chunk (25 bytes)
+		const ihdrEnd = 33
+		if len(buf) < ihdrEnd {
+			return buf
+		}
+		chunk := make([]byte, 8, 12+len(tiff))
+		binary.BigEndian.PutUint32(chunk[0:], uint32(len(tiff)))
+		copy(chunk[4:], "eXIf")
+		chunk = append(chunk, tiff...)
+		crc := crc32.ChecksumIEEE(chunk[4:])
+		chunk = binary.BigEndian.AppendUint32(chunk, crc)
+
+		out := make([]byte, 0, len(buf)+len(chunk))
+		out = append(out, buf[:ihdrEnd]...)
+		out = append(out, chunk...)
+		return append(out, buf[ihdrEnd:]...)
+	}
+
+	return buf
+}
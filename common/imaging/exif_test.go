@@ -0,0 +1,339 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+const (
+	tagMake       = 0x010F
+	tagGPSPointer = 0x8825
+	typeLong      = 4
+)
+
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	value []byte // ASCII values, with their NUL
+	short uint16
+}
+
+// buildTestTiff encodes IFD0 with entries, ASCII values longer than 4 bytes are stored
+// after the IFD.
+func buildTestTiff(order binary.ByteOrder, entries []tiffEntry) []byte {
+	ifdSize := 2 + len(entries)*12 + 4
+	dataOffset := 8 + ifdSize
+
+	var ifd, data bytes.Buffer
+	_ = binary.Write(&ifd, order, uint16(len(entries)))
+	for _, e := range entries {
+		entry := make([]byte, 12)
+		order.PutUint16(entry[0:], e.tag)
+		order.PutUint16(entry[2:], e.typ)
+		switch e.typ {
+		case typeShort:
+			order.PutUint32(entry[4:], 1)
+			order.PutUint16(entry[8:], e.short)
+		case typeLong:
+			order.PutUint32(entry[4:], 1)
+			order.PutUint32(entry[8:], uint32(e.short))
+		default:
+			order.PutUint32(entry[4:], uint32(len(e.value)))
+			if len(e.value) <= 4 {
+				copy(entry[8:], e.value)
+			} else {
+				order.PutUint32(entry[8:], uint32(dataOffset+data.Len()))
+				data.Write(e.value)
+			}
+		}
+		ifd.Write(entry)
+	}
+	_ = binary.Write(&ifd, order, uint32(0))
+
+	var out bytes.Buffer
+	if order == binary.LittleEndian {
+		out.WriteString("II")
+	} else {
+		out.WriteString("MM")
+	}
+	_ = binary.Write(&out, order, uint16(42))
+	_ = binary.Write(&out, order, uint32(8))
+	out.Write(ifd.Bytes())
+	out.Write(data.Bytes())
+
+	return out.Bytes()
+}
+
+func cameraTiff(order binary.ByteOrder, orientation uint16) []byte {
+	return buildTestTiff(order, []tiffEntry{
+		{tag: tagMake, typ: typeASCII, value: []byte("SecretCamera\x00")},
+		{tag: tagOrientation, typ: typeShort, short: orientation},
+		{tag: tagArtist, typ: typeASCII, value: []byte("Ana\x00")},
+		{tag: tagCopyright, typ: typeASCII, value: []byte("(c) Universitas\x00")},
+		{tag: tagGPSPointer, typ: typeLong, short: 0},
+	})
+}
+
+func testImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 60), G: uint8(y * 120), B: 30, A: 255})
+		}
+	}
+
+	return img
+}
+
+func encodeJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// riffWebP wraps chunks, each already encoded with its header, into a WebP container.
+func riffWebP(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	return append(out, body...)
+}
+
+func riffChunk(name string, data []byte) []byte {
+	out := []byte(name)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	out = append(out, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+
+	return out
+}
+
+func pngChunk(name string, data []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	out = append(out, name...)
+	out = append(out, data...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[4:]))
+}
+
+func TestReadMetadata(t *testing.T) {
+	want := Metadata{Orientation: 6, Artist: "Ana", Copyright: "(c) Universitas"}
+
+	tests := []struct {
+		name string
+		buf  func(t *testing.T) []byte
+		want Metadata
+	}{
+		{
+			name: "jpeg little endian",
+			buf:  func(t *testing.T) []byte { return EmbedTiff(encodeJPEG(t), "jpeg", cameraTiff(binary.LittleEndian, 6)) },
+			want: want,
+		},
+		{
+			name: "jpeg big endian",
+			buf:  func(t *testing.T) []byte { return EmbedTiff(encodeJPEG(t), "jpeg", cameraTiff(binary.BigEndian, 6)) },
+			want: want,
+		},
+		{
+			name: "png",
+			buf:  func(t *testing.T) []byte { return EmbedTiff(encodePNG(t), "png", cameraTiff(binary.BigEndian, 6)) },
+			want: want,
+		},
+		{
+			name: "webp with exif header",
+			buf: func(t *testing.T) []byte {
+				exif := append(append([]byte{}, exifHeader...), cameraTiff(binary.LittleEndian, 6)...)
+				return riffWebP(riffChunk("VP8X", make([]byte, 10)), riffChunk("EXIF", exif))
+			},
+			want: want,
+		},
+		{
+			name: "webp without exif header",
+			buf: func(t *testing.T) []byte {
+				return riffWebP(riffChunk("ICCP", []byte("odd")), riffChunk("EXIF", cameraTiff(binary.LittleEndian, 6)))
+			},
+			want: want,
+		},
+		{
+			name: "without exif",
+			buf:  encodeJPEG,
+			want: Metadata{Orientation: 1},
+		},
+		{
+			name: "orientation out of range",
+			buf:  func(t *testing.T) []byte { return EmbedTiff(encodePNG(t), "png", cameraTiff(binary.LittleEndian, 9)) },
+			want: Metadata{Orientation: 1, Artist: "Ana", Copyright: "(c) Universitas"},
+		},
+		{
+			name: "unknown format",
+			buf:  func(t *testing.T) []byte { return []byte("GIF89a") },
+			want: Metadata{Orientation: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadMetadata(tt.buf(t))
+			if err != nil {
+				t.Fatalf("ReadMetadata() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("ReadMetadata() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMetadataMalformed(t *testing.T) {
+	jpegWith := func(segment []byte) []byte {
+		return append([]byte{0xFF, 0xD8}, segment...)
+	}
+	app1 := func(tiff []byte) []byte {
+		payload := append(append([]byte{}, exifHeader...), tiff...)
+		segment := []byte{0xFF, 0xE1}
+		segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+		return append(segment, payload...)
+	}
+	tiff := cameraTiff(binary.LittleEndian, 6)
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{name: "app1 longer than the file", buf: jpegWith([]byte{0xFF, 0xE1, 0xFF, 0xF0, 'E', 'x'})},
+		{name: "segment length below 2", buf: jpegWith([]byte{0xFF, 0xE1, 0x00, 0x01, 0, 0})},
+		{name: "tiff header too short", buf: jpegWith(app1([]byte("II*")))},
+		{name: "invalid byte order", buf: jpegWith(app1([]byte("XX*\x00\x08\x00\x00\x00\x00\x00")))},
+		{name: "ifd offset past the end", buf: jpegWith(app1([]byte("II*\x00\xFF\xFF\xFF\x7F")))},
+		{name: "truncated ifd", buf: jpegWith(app1(tiff[:8+2+12]))},
+		{name: "png chunk longer than the file", buf: append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("eXIf", tiff)[:20]...)},
+		{name: "webp chunk longer than the file", buf: riffWebP(riffChunk("EXIF", tiff)[:20])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ReadMetadata(tt.buf)
+			if err == nil {
+				t.Fatalf("ReadMetadata() error = nil, want an error")
+			}
+			if meta.Orientation != 1 {
+				t.Fatalf("ReadMetadata() orientation = %d, want 1", meta.Orientation)
+			}
+		})
+	}
+}
+
+// TestReadMetadataTruncated cuts a file with EXIF data at every length, none may panic.
+func TestReadMetadataTruncated(t *testing.T) {
+	for _, buf := range [][]byte{
+		EmbedTiff(encodeJPEG(t), "jpeg", cameraTiff(binary.BigEndian, 6)),
+		EmbedTiff(encodePNG(t), "png", cameraTiff(binary.LittleEndian, 6)),
+		riffWebP(riffChunk("EXIF", cameraTiff(binary.LittleEndian, 6))),
+	} {
+		for i := range buf {
+			_, _ = ReadMetadata(buf[:i])
+		}
+	}
+}
+
+func TestBuildTiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		artist    string
+		copyright string
+	}{
+		{name: "both", artist: "Ana Lestari", copyright: "(c) Universitas"},
+		{name: "short values stored inline", artist: "Ana", copyright: "c"},
+		{name: "only copyright", copyright: "(c) Universitas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := &Metadata{}
+			if err := parseTiff(BuildTiff(tt.artist, tt.copyright), meta); err != nil {
+				t.Fatalf("parseTiff() error = %v", err)
+			}
+			if meta.Artist != tt.artist || meta.Copyright != tt.copyright {
+				t.Fatalf("parsed %q, %q, want %q, %q", meta.Artist, meta.Copyright, tt.artist, tt.copyright)
+			}
+		})
+	}
+
+	if tiff := BuildTiff("", ""); tiff != nil {
+		t.Fatalf("BuildTiff() without tags = %v, want nil", tiff)
+	}
+}
+
+func TestEmbedTiff(t *testing.T) {
+	tiff := BuildTiff("Ana", "(c) Universitas")
+
+	tests := []struct {
+		name   string
+		buf    func(t *testing.T) []byte
+		format string
+		decode func(r *bytes.Reader) error
+	}{
+		{
+			name:   "jpeg",
+			buf:    encodeJPEG,
+			format: "jpeg",
+			decode: func(r *bytes.Reader) error { _, err := jpeg.Decode(r); return err },
+		},
+		{
+			name:   "png",
+			buf:    encodePNG,
+			format: "png",
+			// png.Decode checks the CRC of every chunk
+			decode: func(r *bytes.Reader) error { _, err := png.Decode(r); return err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := EmbedTiff(tt.buf(t), tt.format, tiff)
+
+			if err := tt.decode(bytes.NewReader(out)); err != nil {
+				t.Fatalf("decode after EmbedTiff() error = %v", err)
+			}
+			meta, err := ReadMetadata(out)
+			if err != nil {
+				t.Fatalf("ReadMetadata() error = %v", err)
+			}
+			if meta.Artist != "Ana" || meta.Copyright != "(c) Universitas" {
+				t.Fatalf("ReadMetadata() = %+v", *meta)
+			}
+		})
+	}
+
+	webp := riffWebP(riffChunk("VP8L", []byte{0x2F}))
+	if out := EmbedTiff(webp, "webp", tiff); !bytes.Equal(out, webp) {
+		t.Fatal("EmbedTiff() changed a webp file")
+	}
+}
@@ -0,0 +1,58 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApplyOrientation(t *testing.T) {
+	// the source is
+	//   a b c
+	//   d e f
+	// and want holds the rows of the upright result
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{orientation: 0, want: []string{"abc", "def"}},
+		{orientation: 1, want: []string{"abc", "def"}},
+		{orientation: 2, want: []string{"cba", "fed"}},
+		{orientation: 3, want: []string{"fed", "cba"}},
+		{orientation: 4, want: []string{"def", "abc"}},
+		{orientation: 5, want: []string{"ad", "be", "cf"}},
+		{orientation: 6, want: []string{"da", "eb", "fc"}},
+		{orientation: 7, want: []string{"fc", "eb", "da"}},
+		{orientation: 8, want: []string{"cf", "be", "ad"}},
+		{orientation: 9, want: []string{"abc", "def"}},
+	}
+
+	label := func(r byte) color.NRGBA { return color.NRGBA{R: r, A: 255} }
+
+	// an offset origin makes sure the bounds are honored
+	src := image.NewNRGBA(image.Rect(10, 20, 13, 22))
+	for y, row := range []string{"abc", "def"} {
+		for x := range row {
+			src.SetNRGBA(10+x, 20+y, label(row[x]))
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(string(rune('0'+tt.orientation)), func(t *testing.T) {
+			got := ApplyOrientation(src, tt.orientation)
+
+			b := got.Bounds()
+			if b.Dx() != len(tt.want[0]) || b.Dy() != len(tt.want) {
+				t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), len(tt.want[0]), len(tt.want))
+			}
+			for y, row := range tt.want {
+				for x := range row {
+					c := color.NRGBAModel.Convert(got.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+					if c != label(row[x]) {
+						t.Fatalf("pixel (%d, %d) = %q, want %q", x, y, c.R, row[x])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+)
+
+const (
+	placeholderSize = 64
+	blurhashXComp   = 4
+	blurhashYComp   = 3
+)
+
+// Placeholder returns a blurhash string and the dominant color (as #rrggbb) of img.
+// Both are computed on a small copy of the image, the result is only meant for previews.
+func Placeholder(img image.Image) (string, string, error) {
+	small := downscale(img, placeholderSize)
+
+	hash, err := blurhash.Encode(blurhashXComp, blurhashYComp, small)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hash, dominantColor(small), nil
+}
+
+func downscale(img image.Image, size int) *image.NRGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// dominantColor buckets opaque pixels into a 4 bit per channel histogram and
+// returns the average color of the most populated bucket.
+func dominantColor(img *image.NRGBA) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	buckets := make(map[int]*bucket)
+	var best *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				continue
+			}
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			bk, ok := buckets[key]
+			if !ok {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,266 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefetchInterval stops tokens with unknown kids from hammering the JWKS source.
+	minRefetchInterval = 30 * time.Second
+	fetchTimeout       = 10 * time.Second
+)
+
+// KeySet holds the public keys of a JWKS document, indexed by kid. The document is read
+// from a file or an http(s) URL and reloaded periodically, and right away when a token
+// names a kid the set does not know yet, so keys can be rotated without a restart.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+
+	fetchMu sync.Mutex
+}
+
+func NewKeySet(ctx context.Context, source string) (*KeySet, error) {
+	ks := &KeySet{
+		source: source,
+		client: &http.Client{Timeout: fetchTimeout},
+		keys:   make(map[string]any),
+	}
+
+	if err := ks.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Start reloads the document every interval until ctx is done.
+func (ks *KeySet) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Refresh(ctx); err != nil {
+				slog.ErrorContext(ctx, "[KeySet - Start] Error while refresh JWKS", "source", ks.source, "error", err)
+			}
+		}
+	}
+}
+
+// Refresh reloads the document. The current keys are kept when it fails.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ks.fetchMu.Lock()
+	defer ks.fetchMu.Unlock()
+
+	return ks.refresh(ctx)
+}
+
+func (ks *KeySet) refresh(ctx context.Context) error {
+	raw, err := ks.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+
+	slog.InfoContext(ctx, "[KeySet - Refresh] JWKS loaded", "source", ks.source, "keys", len(keys))
+	return nil
+}
+
+// Key returns the public key with the given kid. A token without kid is accepted only
+// when the set holds a single key.
+func (ks *KeySet) Key(ctx context.Context, kid string) (any, error) {
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.fetchMu.Lock()
+	defer ks.fetchMu.Unlock()
+
+	// another caller may have refreshed while we waited
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	fetchedAt := ks.fetchedAt
+	ks.mu.RUnlock()
+	if time.Since(fetchedAt) >= minRefetchInterval {
+		if err := ks.refresh(ctx); err != nil {
+			slog.ErrorContext(ctx, "[KeySet - Key] Error while refresh JWKS", "source", ks.source, "error", err)
+		} else if key, ok := ks.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (ks *KeySet) lookup(kid string) (any, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" {
+		if len(ks.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s while fetching %s", res.Status, ks.source)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS reads the signing keys of a key set. Keys of an unsupported type or curve, or
+// that can not be decoded, are skipped so that one odd key does not reject the whole set.
+func parseJWKS(ctx context.Context, raw []byte) (map[string]any, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "[KeySet - Refresh] Skipping unusable JWKS key", "kid", k.Kid, "kty", k.Kty, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS has no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -1,38 +1,101 @@
 package jwt
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWT struct {
 	secretKey     string
 	tokenDuration time.Duration
+	keySet        *KeySet
+	issuer        string
+	audience      string
+	clockSkew     time.Duration
 }
 
 type CustomClaims struct {
-	StandardClaims jwt.StandardClaims
-	Cred            string `json:"cred"`
-	Role           uint32 `json:"role"`
+	jwt.RegisteredClaims
+	// Legacy holds exp for tokens signed before the standard claims moved to the top level.
+	Legacy *LegacyClaims `json:"StandardClaims,omitempty"`
+	// UserId is zero in tokens issued before the auth service started sending it.
+	UserId uint64 `json:"user_id"`
+	Cred   string `json:"cred"`
+	Role   uint32 `json:"role"`
+	// KodeProdi is the study program of Admin Prodi users.
+	KodeProdi string `json:"kode_prodi,omitempty"`
 }
 
-func NewJWT(secretKey string, tokenDuration time.Duration) *JWT {
-	return &JWT{
-		secretKey,
-		tokenDuration,
+type LegacyClaims struct {
+	ExpiresAt int64 `json:"exp,omitempty"`
+}
+
+type Option func(*JWT)
+
+// WithKeySet verifies RS256 and ES256 tokens against the keys of ks.
+func WithKeySet(ks *KeySet) Option {
+	return func(j *JWT) {
+		j.keySet = ks
+	}
+}
+
+// WithIssuer requires the iss claim to equal issuer.
+func WithIssuer(issuer string) Option {
+	return func(j *JWT) {
+		j.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain audience.
+func WithAudience(audience string) Option {
+	return func(j *JWT) {
+		j.audience = audience
 	}
 }
 
-func (j *JWT) GenerateToken(cred string, role uint32) (string, error) {
+// WithClockSkew tolerates clocks that are off by up to skew when checking exp and nbf.
+func WithClockSkew(skew time.Duration) Option {
+	return func(j *JWT) {
+		j.clockSkew = skew
+	}
+}
+
+// NewJWT verifies HS256 tokens signed with secretKey, if it is not empty, and asymmetric
+// tokens when a key set is given.
+func NewJWT(secretKey string, tokenDuration time.Duration, opts ...Option) *JWT {
+	j := &JWT{
+		secretKey:     secretKey,
+		tokenDuration: tokenDuration,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
+
+func (j *JWT) GenerateToken(userId uint64, cred string, role uint32) (string, error) {
+	if j.secretKey == "" {
+		return "", fmt.Errorf("no secret key to sign tokens with")
+	}
+
+	now := time.Now()
 	claims := &CustomClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Local().Add(j.tokenDuration).Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.tokenDuration)),
 		},
-		Cred:  cred,
-		Role: role,
+		UserId: userId,
+		Cred:   cred,
+		Role:   role,
+	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -40,46 +103,69 @@ func (j *JWT) GenerateToken(cred string, role uint32) (string, error) {
 }
 
 func (j *JWT) Verify(accessToken string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		accessToken,
-		&CustomClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				log.Println("ERROR: [JWT - Verify] Unexpected signing method")
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(j.secretKey), nil
-		},
-	)
-
+	token, err := jwt.ParseWithClaims(accessToken, &CustomClaims{}, j.keyFunc, j.parserOptions()...)
 	if err != nil {
-		log.Println("ERROR: [JWT - Verify] Error while parsing token:", err)
-		// return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+		slog.Error("[JWT - Verify] Error while parsing token", "error", err)
 		return nil, err
 	}
 
 	claims, ok := token.Claims.(*CustomClaims)
 	if !ok {
-		log.Println("ERROR: [JWT - Verify] Invalid token claims")
-		// return nil, status.Error(codes.Unauthenticated, "invalid token claims")
+		slog.Error("[JWT - Verify] Invalid token claims")
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
-	if err := claims.Valid(); err != nil {
-		log.Println("ERROR: [JWT - Verify] Invalid token:", err)
-		return nil, err
+	return claims, nil
+}
+
+func (j *JWT) parserOptions() []jwt.ParserOption {
+	var methods []string
+	if j.secretKey != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if j.keySet != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
 	}
 
-	return claims, nil
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(j.clockSkew),
+	}
+	if j.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		opts = append(opts, jwt.WithAudience(j.audience))
+	}
+
+	return opts
+}
+
+func (j *JWT) keyFunc(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if j.secretKey == "" {
+			return nil, fmt.Errorf("HMAC tokens are not accepted")
+		}
+		return []byte(j.secretKey), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if j.keySet == nil {
+			return nil, fmt.Errorf("asymmetric tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return j.keySet.Key(context.Background(), kid)
+	default:
+		slog.Error("[JWT - Verify] Unexpected signing method", "alg", token.Method.Alg())
+		return nil, fmt.Errorf("unexpected signing method")
+	}
 }
 
-func (c *CustomClaims) Valid() error {
-	// check if the token has expired.
-	if time.Now().Unix() > c.StandardClaims.ExpiresAt {
-		// return status.Error(codes.Unauthenticated, "token has expired")
-		return fmt.Errorf("token has expired")
+// GetExpirationTime falls back to the legacy exp so old tokens still expire.
+func (c *CustomClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	if c.ExpiresAt == nil && c.Legacy != nil && c.Legacy.ExpiresAt != 0 {
+		return jwt.NewNumericDate(time.Unix(c.Legacy.ExpiresAt, 0)), nil
 	}
 
-	return nil
+	return c.RegisteredClaims.GetExpirationTime()
 }
@@ -0,0 +1,373 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "tracerstudy-auth-service"
+	testAudience = "tracerstudy-post-service"
+)
+
+// jwksServer serves the public part of keys, which can be replaced to rotate them.
+type jwksServer struct {
+	mu   sync.Mutex
+	keys map[string]any
+}
+
+func (s *jwksServer) set(keys map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range s.keys {
+		doc.Keys = append(doc.Keys, toJWK(kid, key))
+	}
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+func toJWK(kid string, key any) jwk {
+	enc := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwk{Kty: "RSA", Kid: kid, Use: "sig", N: enc(k.N), E: enc(big.NewInt(int64(k.E)))}
+	case *ecdsa.PrivateKey:
+		return jwk{Kty: "EC", Kid: kid, Use: "sig", Crv: "P-256", X: enc(k.X), Y: enc(k.Y)}
+	}
+
+	panic("unsupported key")
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims *CustomClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	return signed
+}
+
+func validClaims(now time.Time) *CustomClaims {
+	return &CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		UserId: 7,
+		Cred:   "admin@example.com",
+		Role:   1,
+	}
+}
+
+func TestJWTVerifyKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := &jwksServer{keys: map[string]any{"rsa": rsaKey, "ec": ecKey}}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	keySet, err := NewKeySet(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	manager := NewJWT("hmac-secret", time.Hour,
+		WithKeySet(keySet),
+		WithIssuer(testIssuer),
+		WithAudience(testAudience),
+		WithClockSkew(30*time.Second),
+	)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "RS256 signed by a known key",
+			token: func() string { return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, validClaims(now)) },
+		},
+		{
+			name:  "ES256 signed by a known key",
+			token: func() string { return sign(t, jwt.SigningMethodES256, "ec", ecKey, validClaims(now)) },
+		},
+		{
+			name:    "signed by a key outside the set",
+			token:   func() string { return sign(t, jwt.SigningMethodRS256, "rsa", otherKey, validClaims(now)) },
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   func() string { return sign(t, jwt.SigningMethodRS256, "missing", rsaKey, validClaims(now)) },
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := validClaims(now)
+				c.Issuer = "another-service"
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing issuer",
+			token: func() string {
+				c := validClaims(now)
+				c.Issuer = ""
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := validClaims(now)
+				c.Audience = jwt.ClaimStrings{"another-service"}
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "audience among others",
+			token: func() string {
+				c := validClaims(now)
+				c.Audience = jwt.ClaimStrings{"another-service", testAudience}
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+		},
+		{
+			name: "missing audience",
+			token: func() string {
+				c := validClaims(now)
+				c.Audience = nil
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not valid yet",
+			token: func() string {
+				c := validClaims(now)
+				c.NotBefore = jwt.NewNumericDate(now.Add(5 * time.Minute))
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not valid yet within the clock skew",
+			token: func() string {
+				c := validClaims(now)
+				c.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second))
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := validClaims(now)
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "without expiry",
+			token: func() string {
+				c := validClaims(now)
+				c.ExpiresAt = nil
+				return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, c)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "HS256 signed with the secret",
+			token: func() string { return sign(t, jwt.SigningMethodHS256, "", []byte("hmac-secret"), validClaims(now)) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := manager.Verify(tt.token())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Verify() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.UserId != 7 || claims.Cred != "admin@example.com" {
+				t.Fatalf("Verify() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyRejectsHMACWithoutSecret(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(&jwksServer{keys: map[string]any{"rsa": rsaKey}})
+	defer srv.Close()
+
+	keySet, err := NewKeySet(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	manager := NewJWT("", time.Hour, WithKeySet(keySet), WithIssuer(testIssuer), WithAudience(testAudience))
+
+	token := sign(t, jwt.SigningMethodHS256, "", []byte("guessed"), validClaims(time.Now()))
+	if _, err := manager.Verify(token); err == nil {
+		t.Fatal("Verify() error = nil, want HMAC tokens to be rejected")
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := &jwksServer{keys: map[string]any{"old": oldKey}}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	keySet, err := NewKeySet(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	manager := NewJWT("", time.Hour, WithKeySet(keySet), WithIssuer(testIssuer), WithAudience(testAudience))
+
+	jwks.set(map[string]any{"old": oldKey, "new": newKey})
+	token := sign(t, jwt.SigningMethodRS256, "new", newKey, validClaims(time.Now()))
+
+	// the set was just fetched, an unknown kid does not refetch it right away
+	if _, err := manager.Verify(token); err == nil {
+		t.Fatal("Verify() error = nil, want the unknown kid to be rejected within the refetch interval")
+	}
+
+	keySet.mu.Lock()
+	keySet.fetchedAt = time.Now().Add(-minRefetchInterval)
+	keySet.mu.Unlock()
+
+	if _, err := manager.Verify(token); err != nil {
+		t.Fatalf("Verify() error = %v, want the rotated key to be fetched", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaJWK, _ := json.Marshal(toJWK("rsa", rsaKey))
+	ecJWK, _ := json.Marshal(toJWK("ec", ecKey))
+	const (
+		okp         = `{"kty":"OKP","kid":"ed","use":"sig","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+		oct         = `{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}`
+		secp256k1   = `{"kty":"EC","kid":"k1","use":"sig","crv":"secp256k1","x":"AA","y":"AA"}`
+		offCurve    = `{"kty":"EC","kid":"off","use":"sig","crv":"P-256","x":"AQ","y":"AQ"}`
+		encryption  = `{"kty":"RSA","kid":"enc","use":"enc","n":"AQ","e":"AQAB"}`
+		badExponent = `{"kty":"RSA","kid":"bad","use":"sig","n":"AQ","e":"!!"}`
+	)
+	set := func(keys ...string) []byte {
+		return []byte(`{"keys":[` + strings.Join(keys, ",") + `]}`)
+	}
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "supported keys", raw: set(string(rsaJWK), string(ecJWK)), wantKids: []string{"ec", "rsa"}},
+		{name: "unsupported key types are skipped", raw: set(okp, string(rsaJWK), oct), wantKids: []string{"rsa"}},
+		{name: "unsupported curve is skipped", raw: set(secp256k1, string(ecJWK)), wantKids: []string{"ec"}},
+		{name: "malformed keys are skipped", raw: set(offCurve, badExponent, string(ecJWK)), wantKids: []string{"ec"}},
+		{name: "encryption keys are ignored", raw: set(encryption, string(rsaJWK)), wantKids: []string{"rsa"}},
+		{name: "only unsupported keys", raw: set(okp, oct, secp256k1), wantErr: true},
+		{name: "empty set", raw: set(), wantErr: true},
+		{name: "invalid json", raw: []byte(`{"keys":`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS(context.Background(), tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseJWKS() = %v, want an error", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJWKS() error = %v", err)
+			}
+
+			kids := make([]string, 0, len(keys))
+			for kid := range keys {
+				kids = append(kids, kid)
+			}
+			sort.Strings(kids)
+			if strings.Join(kids, ",") != strings.Join(tt.wantKids, ",") {
+				t.Fatalf("parseJWKS() kids = %v, want %v", kids, tt.wantKids)
+			}
+		})
+	}
+}
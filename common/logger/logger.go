@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"tracerstudy-post-service/common/config"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the metadata key and HTTP header that carries the request id.
+const RequestIDHeader = "x-request-id"
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// NewLogger builds a JSON logger writing to stdout at the configured level.
+// Records logged with a context carry its request id and trace id.
+func NewLogger(cfg config.Log) *slog.Logger {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg config.Log) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: ParseLevel(cfg.Level),
+	})
+
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// Init installs the logger as the slog default, the standard log package writes through it as well.
+func Init(cfg config.Log) *slog.Logger {
+	l := NewLogger(cfg)
+	slog.SetDefault(l)
+
+	return l
+}
+
+// ParseLevel maps debug, info, warn and error to their slog level, anything else is info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithRequestID returns a copy of ctx that carries the request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// NewRequestID returns id when the caller sent a usable one, otherwise a new random id.
+func NewRequestID(id string) string {
+	if id != "" && len(id) <= maxRequestIDLength && isPrintable(id) {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns the request id of ctx, empty when there is none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanCtx.TraceID().String()),
+			slog.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tracer_study_post"
+
+var (
+	// RPCDuration observes the handling time of every served RPC by full method and status code.
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "Time spent handling gRPC requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// RPCHandled counts every served RPC by full method and status code.
+	RPCHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "Number of completed gRPC requests.",
+	}, []string{"method", "code"})
+
+	// DBQueryDuration observes gorm statements by operation and table.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent executing database queries.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	// VisitorIncrements counts successful post visitor increments.
+	VisitorIncrements = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "post_visitor_increments_total",
+		Help:      "Number of post visitor increments.",
+	})
+
+	// UploadBytes counts the size of accepted uploads by source (post image, media).
+	UploadBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_bytes_total",
+		Help:      "Bytes received through uploads.",
+	}, []string{"source"})
+
+	// AuthClientDuration observes calls to the auth service by method and status code.
+	AuthClientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "auth_client_duration_seconds",
+		Help:      "Latency of calls to the auth service.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// Handler exposes every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is moved by the tests instead of sleeping.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestMemoryStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = clock.now
+
+	return s, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	// each step advances the clock and then takes a token of key "a"
+	type step struct {
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst up to the limit",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantRetry: time.Second},
+			},
+		},
+		{
+			name: "retry after shrinks as the bucket refills",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{advance: 400 * time.Millisecond, wantRetry: 600 * time.Millisecond},
+				{advance: 600 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+				{wantRetry: time.Second},
+			},
+		},
+		{
+			name: "refill does not exceed the burst",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{advance: time.Hour, wantAllowed: true, wantRemaining: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestMemoryStore()
+
+			for i, st := range tt.steps {
+				clock.advance(st.advance)
+				res, err := s.Take(context.Background(), "a", limit)
+				if err != nil {
+					t.Fatalf("step %d: Take() error = %v", i, err)
+				}
+				if res.Allowed != st.wantAllowed || res.Remaining != st.wantRemaining || res.RetryAfter != st.wantRetry {
+					t.Fatalf("step %d: Take() = %+v, want allowed %v, remaining %d, retry after %s",
+						i, res, st.wantAllowed, st.wantRemaining, st.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	s, _ := newTestMemoryStore()
+	limit := Limit{Requests: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	if res, _ := s.Take(ctx, "a", limit); !res.Allowed {
+		t.Fatal("first Take() of a denied")
+	}
+	if res, _ := s.Take(ctx, "a", limit); res.Allowed {
+		t.Fatal("second Take() of a allowed")
+	}
+	if res, _ := s.Take(ctx, "b", limit); !res.Allowed {
+		t.Fatal("Take() of b denied after a used its bucket up")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s, clock := newTestMemoryStore()
+	ctx := context.Background()
+
+	if _, err := s.Take(ctx, "short", Limit{Requests: 1, Period: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Take(ctx, "long", Limit{Requests: 1, Period: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.advance(time.Minute)
+	s.sweep()
+
+	if _, ok := s.buckets["short"]; ok {
+		t.Error("bucket idle for longer than its period was kept")
+	}
+	if _, ok := s.buckets["long"]; !ok {
+		t.Error("bucket still refilling was dropped")
+	}
+
+	// a dropped bucket starts full again, as it would have refilled by now
+	if res, _ := s.Take(ctx, "short", Limit{Requests: 1, Period: time.Second}); !res.Allowed {
+		t.Error("Take() after the sweep denied")
+	}
+}
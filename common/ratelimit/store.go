@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Requests calls per Period. Tokens refill continuously, so a caller that
+// used up the bucket gets a new call every Period/Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next token, zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps one token bucket per key. MemoryStore is local to a replica, a store
+// shared between replicas, e.g. backed by Redis, only has to implement Take.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
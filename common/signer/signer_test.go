@@ -0,0 +1,146 @@
+package signer
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/config"
+)
+
+const testPath = "/uploads/private/report.pdf"
+
+func signedQuery(t *testing.T, s *Signer, path string, expiresAt time.Time) url.Values {
+	t.Helper()
+
+	u, err := url.Parse(s.Sign(path, expiresAt))
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if u.Path != path {
+		t.Fatalf("Sign() path = %q, want %q", u.Path, path)
+	}
+
+	return u.Query()
+}
+
+func TestSignerVerify(t *testing.T) {
+	s := NewSigner("media-secret")
+	valid := signedQuery(t, s, testPath, time.Now().Add(time.Minute))
+
+	tests := []struct {
+		name    string
+		path    string
+		query   func() url.Values
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			path:  testPath,
+			query: func() url.Values { return valid },
+		},
+		{
+			name:    "expired",
+			path:    testPath,
+			query:   func() url.Values { return signedQuery(t, s, testPath, time.Now().Add(-time.Second)) },
+			wantErr: ErrExpired,
+		},
+		{
+			name:    "tampered path",
+			path:    "/uploads/private/other.pdf",
+			query:   func() url.Values { return valid },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "tampered expiry",
+			path: testPath,
+			query: func() url.Values {
+				q := url.Values{QuerySignature: {valid.Get(QuerySignature)}}
+				q.Set(QueryExpires, "4102444800")
+				return q
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed with another key",
+			path: testPath,
+			query: func() url.Values {
+				return signedQuery(t, NewSigner("other-secret"), testPath, time.Now().Add(time.Minute))
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "missing signature",
+			path:    testPath,
+			query:   func() url.Values { return url.Values{QueryExpires: {valid.Get(QueryExpires)}} },
+			wantErr: ErrMissingSignature,
+		},
+		{
+			name:    "missing expiry",
+			path:    testPath,
+			query:   func() url.Values { return url.Values{QuerySignature: {valid.Get(QuerySignature)}} },
+			wantErr: ErrMissingSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Verify(tt.path, tt.query())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewMediaSigner(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantKey string
+		wantErr bool
+	}{
+		{
+			name:    "signing key",
+			cfg:     config.Config{Media: config.Media{SigningKey: "media"}, JWT: config.JWTConfig{JwtSecretKey: "jwt"}},
+			wantKey: "media",
+		},
+		{
+			name:    "falls back to the jwt secret",
+			cfg:     config.Config{JWT: config.JWTConfig{JwtSecretKey: "jwt"}},
+			wantKey: "jwt",
+		},
+		{
+			name:    "signing key with jwks",
+			cfg:     config.Config{Media: config.Media{SigningKey: "media"}, JWT: config.JWTConfig{JWKS: "https://auth.example.com/jwks.json"}},
+			wantKey: "media",
+		},
+		{
+			name:    "jwks without signing key",
+			cfg:     config.Config{JWT: config.JWTConfig{JWKS: "https://auth.example.com/jwks.json", JwtSecretKey: "jwt"}},
+			wantErr: true,
+		},
+		{
+			name:    "no key at all",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewMediaSigner(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewMediaSigner() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMediaSigner() error = %v", err)
+			}
+			if string(s.key) != tt.wantKey {
+				t.Fatalf("NewMediaSigner() key = %q, want %q", s.key, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// MemoryStorage keeps objects in process memory. It is meant for tests and local experiments.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		objects: make(map[string]*memoryObject),
+	}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, data []byte) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = &memoryObject{
+		data:    append([]byte(nil), data...),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), obj.data...), nil
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &Object{
+		Key:     key,
+		Size:    int64(len(obj.data)),
+		ModTime: obj.modTime,
+	}, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]*Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var objects []*Object
+	for key, obj := range s.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		objects = append(objects, &Object{
+			Key:     key,
+			Size:    int64(len(obj.data)),
+			ModTime: obj.modTime,
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	return objects, nil
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/config"
+)
+
+const testBucket = "tracer"
+
+// fakeS3 implements the path style object calls the driver makes.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+type listBucketResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Name     string
+	Prefix   string
+	KeyCount int
+	Contents []listObject
+}
+
+type listObject struct {
+	Key          string
+	Size         int64
+	LastModified string
+	ETag         string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != testBucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+
+	switch {
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		prefix := r.URL.Query().Get("prefix")
+		res := listBucketResult{Name: bucket, Prefix: prefix}
+		for k, data := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				res.Contents = append(res.Contents, listObject{Key: k, Size: int64(len(data)), LastModified: "2024-01-02T03:04:05.000Z", ETag: `"etag"`})
+			}
+		}
+		sort.Slice(res.Contents, func(i, j int) bool { return res.Contents[i].Key < res.Contents[j].Key })
+		res.KeyCount = len(res.Contents)
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeAwsChunked(data)
+		}
+		f.objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", modTime)
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// decodeAwsChunked strips the per chunk signatures of a streaming signed upload.
+func decodeAwsChunked(body []byte) []byte {
+	var data []byte
+	for len(body) > 0 {
+		header, rest, ok := strings.Cut(string(body), "\r\n")
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := strings.Cut(header, ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			break
+		}
+		data = append(data, rest[:size]...)
+		body = []byte(strings.TrimPrefix(rest[size:], "\r\n"))
+	}
+
+	return data
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, "<Error><Code>"+code+"</Code><Message>"+code+"</Message></Error>")
+}
+
+func newTestS3Storage(t *testing.T, prefix string) (*S3Storage, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3Storage(config.S3{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    testBucket,
+		AccessKey: "access",
+		SecretKey: "secret",
+		Prefix:    prefix,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+
+	return s, fake
+}
+
+func TestS3Storage(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		key       string
+		storedKey string
+	}{
+		{name: "without prefix", key: "image.jpg", storedKey: "image.jpg"},
+		{name: "with prefix", prefix: "post/", key: "image.jpg", storedKey: "post/image.jpg"},
+		{name: "nested key", prefix: "post/", key: "2024/image.jpg", storedKey: "post/2024/image.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, fake := newTestS3Storage(t, tt.prefix)
+			data := []byte("image data")
+
+			if err := s.Put(ctx, tt.key, data); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if got := string(fake.objects[tt.storedKey]); got != string(data) {
+				t.Fatalf("stored object %q = %q, want %q", tt.storedKey, got, data)
+			}
+
+			got, err := s.Get(ctx, tt.key)
+			if err != nil || string(got) != string(data) {
+				t.Fatalf("Get() = %q, %v, want %q", got, err, data)
+			}
+
+			obj, err := s.Stat(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if obj.Key != tt.key || obj.Size != int64(len(data)) || obj.ModTime.IsZero() {
+				t.Fatalf("Stat() = %+v", obj)
+			}
+
+			objects, err := s.List(ctx, "")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(objects) != 1 || objects[0].Key != tt.key {
+				t.Fatalf("List() = %+v, want only %q", objects, tt.key)
+			}
+
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := s.Get(ctx, tt.key); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get() after Delete() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func(ctx context.Context, s *S3Storage) error
+		wantErr error
+	}{
+		{
+			name:    "put empty key",
+			op:      func(ctx context.Context, s *S3Storage) error { return s.Put(ctx, "", []byte("x")) },
+			wantErr: ErrInvalidKey,
+		},
+		{
+			name: "get missing key",
+			op: func(ctx context.Context, s *S3Storage) error {
+				_, err := s.Get(ctx, "missing.jpg")
+				return err
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "stat missing key",
+			op: func(ctx context.Context, s *S3Storage) error {
+				_, err := s.Stat(ctx, "missing.jpg")
+				return err
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "delete missing key",
+			op:   func(ctx context.Context, s *S3Storage) error { return s.Delete(ctx, "missing.jpg") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestS3Storage(t, "post/")
+			if err := tt.op(context.Background(), s); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
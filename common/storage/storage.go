@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tracerstudy-post-service/common/config"
+)
+
+const (
+	DriverLocal  = "local"
+	DriverS3     = "s3"
+	DriverMemory = "memory"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage is implemented by every file storage driver. Keys are slash separated
+// paths relative to the storage root, e.g. "image.jpg" or "2024/image.jpg".
+// Deleting a key that does not exist is not an error.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Stat(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]*Object, error)
+}
+
+// KeyFromPublicPath maps a public path served under publicPrefix back to its key. A path
+// outside the prefix, such as a CDN or absolute URL, is not stored here and reports false.
+func KeyFromPublicPath(publicPrefix, path string) (string, bool) {
+	key, ok := strings.CutPrefix(path, publicPrefix)
+	if !ok || key == "" {
+		return "", false
+	}
+
+	return key, true
+}
+
+const healthCheckKey = ".healthcheck"
+
+// CheckWritable writes and removes a probe object to verify the driver accepts writes.
+func CheckWritable(ctx context.Context, s Storage) error {
+	if err := s.Put(ctx, healthCheckKey, []byte("ok")); err != nil {
+		return err
+	}
+
+	return s.Delete(ctx, healthCheckKey)
+}
+
+// NewStorage builds the driver selected by STORAGE_DRIVER.
+func NewStorage(cfg config.Config) (Storage, error) {
+	switch cfg.Storage.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.StoragePath)
+	case DriverS3:
+		return NewS3Storage(cfg.Storage.S3)
+	case DriverMemory:
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIP returns the address of the caller. Calls relayed by the REST gateway come
+// from loopback, for those the address is read from x-forwarded-for instead. Only the
+// entries appended by the gateway and by the trustedProxies reverse proxies in front
+// of it are trusted, the ones before them are whatever the client sent.
+func ClientIP(ctx context.Context, trustedProxies int) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return host
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var forwarded []string
+	for _, value := range md.Get("x-forwarded-for") {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				forwarded = append(forwarded, entry)
+			}
+		}
+	}
+	if len(forwarded) == 0 {
+		return host
+	}
+
+	i := len(forwarded) - 1 - trustedProxies
+	if i < 0 {
+		i = 0
+	}
+
+	return forwarded[i]
+}
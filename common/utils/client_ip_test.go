@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		peer           string
+		forwardedFor   []string
+		trustedProxies int
+		want           string
+	}{
+		{
+			name: "direct caller",
+			peer: "203.0.113.7:51234",
+			want: "203.0.113.7",
+		},
+		{
+			name:         "direct caller cannot set the forwarded address",
+			peer:         "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "gateway without proxies",
+			peer:         "127.0.0.1:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "spoofed entries before the gateway's are ignored",
+			peer:         "127.0.0.1:51234",
+			forwardedFor: []string{"10.0.0.1, 198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:           "entry appended by a trusted proxy",
+			peer:           "[::1]:51234",
+			forwardedFor:   []string{"10.0.0.1, 198.51.100.1", "192.0.2.10"},
+			trustedProxies: 1,
+			want:           "198.51.100.1",
+		},
+		{
+			name:           "more trusted proxies than entries",
+			peer:           "127.0.0.1:51234",
+			forwardedFor:   []string{"198.51.100.1, 192.0.2.10"},
+			trustedProxies: 5,
+			want:           "198.51.100.1",
+		},
+		{
+			name:         "blank entries are skipped",
+			peer:         "127.0.0.1:51234",
+			forwardedFor: []string{"198.51.100.1, ,"},
+			want:         "198.51.100.1",
+		},
+		{
+			name: "gateway without forwarded address",
+			peer: "127.0.0.1:51234",
+			want: "127.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			if tt.forwardedFor != nil {
+				md := metadata.MD{"x-forwarded-for": tt.forwardedFor}
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			if got := ClientIP(ctx, tt.trustedProxies); got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutPeer(t *testing.T) {
+	if got := ClientIP(context.Background(), 0); got != "" {
+		t.Fatalf("ClientIP() = %q, want empty", got)
+	}
+}
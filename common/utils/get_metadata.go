@@ -2,24 +2,23 @@ package utils
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"tracerstudy-post-service/common/errors"
 
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
 func GetMetadataAuthorization(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		log.Println("ERROR: [Utils - GetMetadataAuthorization] Metadata is not provided")
-		return "", status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		slog.ErrorContext(ctx, "[Utils - GetMetadataAuthorization] Metadata is not provided")
+		return "", errors.Unauthenticated(errors.ReasonMissingCredentials, nil, "metadata is not provided")
 	}
 
 	values, ok := md["authorization"]
 	if !ok || len(values) == 0 {
-		log.Println("ERROR: [Utils - GetMetadataAuthorization] Authorization token is not provided")
-		return "", status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		slog.ErrorContext(ctx, "[Utils - GetMetadataAuthorization] Authorization token is not provided")
+		return "", errors.Unauthenticated(errors.ReasonMissingCredentials, nil, "authorization token is not provided")
 	}
 
 	authHeader := values[0]
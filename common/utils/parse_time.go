@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"time"
+	"tracerstudy-post-service/common/errors"
+)
+
+// ParseTimestamp reads an optional RFC 3339 request field, empty gives the zero time.
+func ParseTimestamp(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Validation(errors.ReasonInvalidTimestamp, err, "%s must be an RFC 3339 timestamp", field).WithMetadata("field", field)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,195 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ExistsCheck reports whether the record with id exists.
+type ExistsCheck func(ctx context.Context, id uint64) (bool, error)
+
+// Validator checks requests against the rules declared in proto/validate.proto:
+// the required method option and the rules field option.
+type Validator struct {
+	mu     sync.RWMutex
+	exists map[string]ExistsCheck
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		exists: make(map[string]ExistsCheck),
+	}
+}
+
+// RegisterExistsCheck makes name usable as the exists rule of a field.
+func (v *Validator) RegisterExistsCheck(name string, check ExistsCheck) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.exists[name] = check
+}
+
+// Validate checks msg, the request of the full gRPC method name. Violations are returned
+// as one InvalidArgument status carrying google.rpc.BadRequest field violations.
+func (v *Validator) Validate(ctx context.Context, method string, msg proto.Message) error {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, name := range requiredFields(method) {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil || isEmpty(m, fd) {
+			violations = append(violations, violation(name, "is required"))
+		}
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+
+		rules := fieldRules(fd)
+		if rules == nil {
+			continue
+		}
+
+		violation, err := v.checkField(ctx, fd, m.Get(fd), rules)
+		if err != nil {
+			return err
+		}
+		if violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return invalidArgument(violations)
+}
+
+func (v *Validator) checkField(ctx context.Context, fd protoreflect.FieldDescriptor, value protoreflect.Value, rules *pb.FieldRules) (*errdetails.BadRequest_FieldViolation, error) {
+	name := string(fd.Name())
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s := value.String()
+		if max := rules.GetMaxLen(); max > 0 && uint32(utf8.RuneCountInString(strings.TrimSpace(s))) > max {
+			return violation(name, fmt.Sprintf("must be at most %d characters", max)), nil
+		}
+		if allowed := rules.GetIn(); len(allowed) > 0 && !contains(allowed, s) {
+			return violation(name, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Uint32Kind:
+		if rules.GetExists() == "" {
+			return nil, nil
+		}
+
+		v.mu.RLock()
+		check, ok := v.exists[rules.GetExists()]
+		v.mu.RUnlock()
+		if !ok {
+			slog.ErrorContext(ctx, "[Validator - checkField] Exists check is not registered", "check", rules.GetExists(), "field", name)
+			return nil, fmt.Errorf("exists check %q is not registered", rules.GetExists())
+		}
+
+		found, err := check(ctx, value.Uint())
+		if err != nil {
+			return nil, fmt.Errorf("error while check %s: %w", name, err)
+		}
+		if !found {
+			return violation(name, fmt.Sprintf("must reference an existing %s", rules.GetExists())), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// requiredFields reads the required option of the method, e.g. "/tracer_study_grpc.PostService/CreatePost".
+func requiredFields(method string) []string {
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil
+	}
+
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil
+	}
+
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return nil
+	}
+
+	return proto.GetExtension(opts, pb.E_Required).([]string)
+}
+
+// isEmpty reports whether fd is unset, strings made of whitespace only count as unset.
+func isEmpty(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	if !m.Has(fd) {
+		return true
+	}
+	if fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+		return strings.TrimSpace(m.Get(fd).String()) == ""
+	}
+
+	return false
+}
+
+func fieldRules(fd protoreflect.FieldDescriptor) *pb.FieldRules {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, pb.E_Rules) {
+		return nil
+	}
+
+	return proto.GetExtension(opts, pb.E_Rules).(*pb.FieldRules)
+}
+
+func violation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: field + " " + description,
+	}
+}
+
+func invalidArgument(violations []*errdetails.BadRequest_FieldViolation) error {
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		descriptions = append(descriptions, v.GetDescription())
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(descriptions, "; "))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
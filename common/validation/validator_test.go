@@ -0,0 +1,156 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func violatedFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Fatalf("error = %v, want an InvalidArgument status", err)
+	}
+
+	var fields []string
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+func TestValidatorValidate(t *testing.T) {
+	existing := map[uint64]bool{1: true}
+	v := NewValidator()
+	v.RegisterExistsCheck("post", func(ctx context.Context, id uint64) (bool, error) {
+		return existing[id], nil
+	})
+	v.RegisterExistsCheck("comment", func(ctx context.Context, id uint64) (bool, error) {
+		return false, errors.New("database is down")
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		msg        proto.Message
+		wantFields []string
+		wantErr    bool
+	}{
+		{
+			name:   "create post with every required field",
+			method: "/tracer_study_grpc.PostService/CreatePost",
+			msg:    &pb.CreatePostRequest{Title: "Reuni", Content: "isi", Type: "berita"},
+		},
+		{
+			name:   "create post with another allowed type",
+			method: "/tracer_study_grpc.PostService/CreatePost",
+			msg:    &pb.CreatePostRequest{Title: "Reuni", Content: "isi", Type: "artikel"},
+		},
+		{
+			name:       "create post with an unknown type",
+			method:     "/tracer_study_grpc.PostService/CreatePost",
+			msg:        &pb.CreatePostRequest{Title: "Reuni", Content: "isi", Type: "iklan"},
+			wantFields: []string{"type"},
+		},
+		{
+			name:       "create post with a whitespace only title",
+			method:     "/tracer_study_grpc.PostService/CreatePost",
+			msg:        &pb.CreatePostRequest{Title: " \t\n", Content: "isi", Type: "berita"},
+			wantFields: []string{"title"},
+		},
+		{
+			name:   "create post with a title padded up to the limit",
+			method: "/tracer_study_grpc.PostService/CreatePost",
+			msg:    &pb.CreatePostRequest{Title: "  " + strings.Repeat("a", 255) + "  ", Content: "isi", Type: "berita"},
+		},
+		{
+			name:       "create post without required fields",
+			method:     "/tracer_study_grpc.PostService/CreatePost",
+			msg:        &pb.CreatePostRequest{ImageCaption: "caption"},
+			wantFields: []string{"content", "title", "type"},
+		},
+		{
+			name:       "create post with a too long title",
+			method:     "/tracer_study_grpc.PostService/CreatePost",
+			msg:        &pb.CreatePostRequest{Title: strings.Repeat("a", 256), Content: "isi", Type: "berita"},
+			wantFields: []string{"title"},
+		},
+		{
+			name:   "update post with only an id",
+			method: "/tracer_study_grpc.PostService/UpdatePost",
+			msg:    &pb.CreatePostRequest{Id: 1},
+		},
+		{
+			name:       "update post without id",
+			method:     "/tracer_study_grpc.PostService/UpdatePost",
+			msg:        &pb.CreatePostRequest{Title: "Reuni"},
+			wantFields: []string{"id"},
+		},
+		{
+			name:   "comment on an existing post",
+			method: "/tracer_study_grpc.CommentService/CreateComment",
+			msg:    &pb.Comment{PostId: 1, Name: "alumni", Content: "selamat"},
+		},
+		{
+			name:       "comment on a missing post",
+			method:     "/tracer_study_grpc.CommentService/CreateComment",
+			msg:        &pb.Comment{PostId: 2, Name: "alumni", Content: "selamat"},
+			wantFields: []string{"post_id"},
+		},
+		{
+			name:    "exists check failure is not a violation",
+			method:  "/tracer_study_grpc.CommentService/ReplyComment",
+			msg:     &pb.Comment{CommentId: 1, Name: "alumni", Content: "selamat"},
+			wantErr: true,
+		},
+		{
+			name:       "value outside the allowed set",
+			method:     "/tracer_study_grpc.MediaService/UploadMedia",
+			msg:        &pb.UploadMediaRequest{FileName: "a.pdf", FileBuffer: []byte("x"), Visibility: "secret"},
+			wantFields: []string{"visibility"},
+		},
+		{
+			name:   "method without rules",
+			method: "/tracer_study_grpc.PostService/GetAllPosts",
+			msg:    &pb.CreatePostRequest{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(context.Background(), tt.method, tt.msg)
+
+			switch {
+			case tt.wantErr:
+				if _, ok := status.FromError(err); err == nil || ok {
+					t.Fatalf("error = %v, want a plain error", err)
+				}
+			case tt.wantFields == nil:
+				if err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+			default:
+				if got := violatedFields(t, err); strings.Join(got, ",") != strings.Join(tt.wantFields, ",") {
+					t.Fatalf("violated fields = %v, want %v", got, tt.wantFields)
+				}
+			}
+		})
+	}
+}
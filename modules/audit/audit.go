@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"tracerstudy-post-service/common/config"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/modules/audit/builder"
+	"tracerstudy-post-service/modules/audit/entity"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB) {
+	audit := builder.BuildAuditHandler(cfg, db)
+	pb.RegisterAuditServiceServer(server, audit)
+}
+
+// Migrate creates the audit_events table, every mutation of a post or comment writes to it.
+func Migrate(db *gorm.DB) error {
+	return gormConn.Migrate(db, &entity.AuditEvent{})
+}
@@ -0,0 +1,21 @@
+package builder
+
+import (
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/audit/handler"
+	"tracerstudy-post-service/modules/audit/repository"
+	"tracerstudy-post-service/modules/audit/service"
+
+	"gorm.io/gorm"
+)
+
+// BuildAuditService is also used by the post and comment modules to record their writes.
+func BuildAuditService(cfg config.Config, db *gorm.DB) *service.AuditService {
+	auditRepo := repository.NewAuditRepository(db)
+
+	return service.NewAuditService(cfg, auditRepo)
+}
+
+func BuildAuditHandler(cfg config.Config, db *gorm.DB) *handler.AuditHandler {
+	return handler.NewAuditHandler(cfg, BuildAuditService(cfg, db))
+}
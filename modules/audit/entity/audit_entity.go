@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+	"tracerstudy-post-service/pb"
+)
+
+const (
+	AuditEventTableName = "audit_events"
+
+	ResourcePost    = "post"
+	ResourceComment = "comment"
+
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// AuditEvent records one mutation of a post or comment and who made it.
+type AuditEvent struct {
+	Id         uint64    `json:"id"`
+	Resource   string    `gorm:"index:idx_audit_resource" json:"resource"`
+	ResourceId uint64    `gorm:"index:idx_audit_resource" json:"resource_id"`
+	Action     string    `json:"action"`
+	ActorId    uint64    `gorm:"index" json:"actor_id"`
+	ActorCred  string    `json:"actor_cred"`
+	ActorRole  uint32    `json:"actor_role"`
+	ClientIp   string    `json:"client_ip"`
+	RequestId  string    `json:"request_id"`
+	Changes    Changes   `gorm:"type:json" json:"changes"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// Change holds the JSON values of a field before and after the mutation, nil when the
+// field did not exist on that side, e.g. before a create.
+type Change struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Changes maps a field name to how it changed.
+type Changes map[string]*Change
+
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(c)
+}
+
+func (c *Changes) Scan(value any) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("unsupported type %T for audit changes", value)
+	}
+
+	return json.Unmarshal(data, c)
+}
+
+func (a *AuditEvent) TableName() string {
+	return AuditEventTableName
+}
+
+// AuditFilter narrows ListAuditEvents, zero fields are ignored.
+type AuditFilter struct {
+	Resource   string
+	ResourceId uint64
+	Action     string
+	ActorId    uint64
+	ActorCred  string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+func ConvertEntityToProto(a *AuditEvent) *pb.AuditEvent {
+	res := &pb.AuditEvent{
+		Id:         a.Id,
+		Resource:   a.Resource,
+		ResourceId: a.ResourceId,
+		Action:     a.Action,
+		ActorId:    a.ActorId,
+		ActorCred:  a.ActorCred,
+		ActorRole:  a.ActorRole,
+		ClientIp:   a.ClientIp,
+		RequestId:  a.RequestId,
+		CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+	}
+
+	if len(a.Changes) > 0 {
+		res.Changes = make(map[string]*pb.FieldChange, len(a.Changes))
+		for field, c := range a.Changes {
+			res.Changes[field] = &pb.FieldChange{
+				Before: string(c.Before),
+				After:  string(c.After),
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/utils"
+	"tracerstudy-post-service/modules/audit/entity"
+	"tracerstudy-post-service/modules/audit/service"
+	"tracerstudy-post-service/pb"
+)
+
+type AuditHandler struct {
+	pb.UnimplementedAuditServiceServer
+	config   config.Config
+	auditSvc service.AuditServiceUseCase
+}
+
+func NewAuditHandler(config config.Config, auditService service.AuditServiceUseCase) *AuditHandler {
+	return &AuditHandler{
+		config:   config,
+		auditSvc: auditService,
+	}
+}
+
+func (ah *AuditHandler) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	from, err := utils.ParseTimestamp("from", req.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := utils.ParseTimestamp("to", req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := ah.auditSvc.FindAll(ctx, &entity.AuditFilter{
+		Resource:   req.GetResource(),
+		ResourceId: req.GetResourceId(),
+		Action:     req.GetAction(),
+		ActorId:    req.GetActorId(),
+		ActorCred:  req.GetActorCred(),
+		From:       from,
+		To:         to,
+		Limit:      int(req.GetLimit()),
+		Offset:     int(req.GetOffset()),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[AuditHandler - ListAuditEvents] Error while list audit events", "error", err)
+		return nil, err
+	}
+
+	var eventArr []*pb.AuditEvent
+	for _, e := range events {
+		eventArr = append(eventArr, entity.ConvertEntityToProto(e))
+	}
+
+	return &pb.ListAuditEventsResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "list audit events success",
+		Data:    eventArr,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/tracing"
+	"tracerstudy-post-service/modules/audit/entity"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+type AuditRepositoryUseCase interface {
+	FindAll(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEvent, error)
+	Create(ctx context.Context, req *entity.AuditEvent) (*entity.AuditEvent, error)
+}
+
+func (a *AuditRepository) FindAll(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "AuditRepository - FindAll")
+	defer span.End()
+
+	query := gormConn.Conn(ctx, a.db).WithContext(ctxSpan)
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.ResourceId != 0 {
+		query = query.Where("resource_id = ?", filter.ResourceId)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorId != 0 {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+	if filter.ActorCred != "" {
+		query = query.Where("actor_cred = ?", filter.ActorCred)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var events []*entity.AuditEvent
+	if err := query.Order("created_at desc, id desc").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error; err != nil {
+		slog.ErrorContext(ctx, "[AuditRepository - FindAll] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return events, nil
+}
+
+func (a *AuditRepository) Create(ctx context.Context, req *entity.AuditEvent) (*entity.AuditEvent, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "AuditRepository - Create")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, a.db).WithContext(ctxSpan).Create(req).Error; err != nil {
+		slog.ErrorContext(ctx, "[AuditRepository - Create] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/logger"
+	"tracerstudy-post-service/common/utils"
+	"tracerstudy-post-service/modules/audit/entity"
+	"tracerstudy-post-service/modules/audit/repository"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ignoredFields change on every write and would only add noise to the diff.
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+type AuditService struct {
+	cfg             config.Config
+	auditRepository repository.AuditRepositoryUseCase
+}
+
+func NewAuditService(cfg config.Config, auditRepository repository.AuditRepositoryUseCase) *AuditService {
+	return &AuditService{
+		cfg:             cfg,
+		auditRepository: auditRepository,
+	}
+}
+
+type AuditServiceUseCase interface {
+	FindAll(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEvent, error)
+	Record(ctx context.Context, resource string, resourceId uint64, action string, before, after any) error
+}
+
+func (svc *AuditService) FindAll(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	res, err := svc.auditRepository.FindAll(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "[AuditService - FindAll] Error while find audit events", "error", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Record stores who changed resource and how. before is nil for a create and after is
+// nil for a delete. Callers run it in the transaction of the mutation, so that a change
+// is never stored without its audit event.
+func (svc *AuditService) Record(ctx context.Context, resource string, resourceId uint64, action string, before, after any) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		slog.ErrorContext(ctx, "[AuditService - Record] Error while diff audit states", "resource", resource, "id", resourceId, "error", err)
+		return err
+	}
+
+	event := &entity.AuditEvent{
+		Resource:   resource,
+		ResourceId: resourceId,
+		Action:     action,
+		ClientIp:   utils.ClientIP(ctx, svc.cfg.HTTP.TrustedProxies),
+		RequestId:  logger.RequestID(ctx),
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}
+	if principal, ok := authorization.PrincipalFromContext(ctx); ok {
+		event.ActorId = principal.UserId
+		event.ActorCred = principal.Cred
+		event.ActorRole = principal.Role
+	}
+
+	if _, err := svc.auditRepository.Create(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "[AuditService - Record] Error while create audit event",
+			"resource", resource, "id", resourceId, "action", action, "actor", event.ActorCred, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// diff returns the fields whose JSON value differs between before and after.
+func diff(before, after any) (entity.Changes, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(entity.Changes)
+	for field, value := range b {
+		if ignoredFields[field] {
+			continue
+		}
+		if other, ok := a[field]; !ok || !bytes.Equal(value, other) {
+			changes[field] = &entity.Change{Before: value, After: a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok && !ignoredFields[field] {
+			changes[field] = &entity.Change{After: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func fields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
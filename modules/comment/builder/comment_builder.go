@@ -2,6 +2,7 @@ package builder
 
 import (
 	"tracerstudy-post-service/common/config"
+	auditBuilder "tracerstudy-post-service/modules/audit/builder"
 	"tracerstudy-post-service/modules/comment/handler"
 	"tracerstudy-post-service/modules/comment/repository"
 	"tracerstudy-post-service/modules/comment/service"
@@ -12,7 +13,7 @@ import (
 
 func BuildCommentHandler(cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) *handler.CommentHandler {
 	commentRepo := repository.NewCommentRepository(db)
-	commentSvc := service.NewCommentService(cfg, commentRepo)
+	commentSvc := service.NewCommentService(cfg, commentRepo, auditBuilder.BuildAuditService(cfg, db))
 
 	return handler.NewCommentHandler(cfg, commentSvc)
 }
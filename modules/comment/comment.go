@@ -1,8 +1,10 @@
 package comment
 
 import (
+	"net/http"
 	"tracerstudy-post-service/common/config"
 	"tracerstudy-post-service/modules/comment/builder"
+	"tracerstudy-post-service/modules/comment/gateway"
 	"tracerstudy-post-service/pb"
 
 	"google.golang.org/grpc"
@@ -13,3 +15,7 @@ func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grp
 	comment := builder.BuildCommentHandler(cfg, db, grpcConn)
 	pb.RegisterCommentServiceServer(server, comment)
 }
+
+func InitRest(mux *http.ServeMux, grpcConn *grpc.ClientConn) {
+	gateway.NewCommentGateway(pb.NewCommentServiceClient(grpcConn)).Register(mux)
+}
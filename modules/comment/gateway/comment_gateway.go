@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"tracerstudy-post-service/pb"
+	"tracerstudy-post-service/server/gateway"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type CommentGateway struct {
+	client pb.CommentServiceClient
+}
+
+func NewCommentGateway(client pb.CommentServiceClient) *CommentGateway {
+	return &CommentGateway{
+		client: client,
+	}
+}
+
+func (cg *CommentGateway) Register(mux *http.ServeMux) {
+	gateway.Handle(mux, "GET /v1/comments", cg.GetAllComments)
+	gateway.Handle(mux, "GET /v1/comments/{id}", cg.GetCommentById)
+	gateway.Handle(mux, "GET /v1/posts/{id}/comments", cg.GetCommentsByPostId)
+	gateway.Handle(mux, "POST /v1/posts/{id}/comments", cg.CreateComment)
+	gateway.Handle(mux, "POST /v1/comments/{id}/replies", cg.ReplyComment)
+	gateway.Handle(mux, "DELETE /v1/comments/{id}", cg.DeleteComment)
+	gateway.Handle(mux, "POST /v1/comments/{id}/restore", cg.RestoreComment)
+}
+
+func (cg *CommentGateway) GetAllComments(ctx context.Context, r *http.Request) (proto.Message, error) {
+	return cg.client.GetAllComments(ctx, &emptypb.Empty{})
+}
+
+func (cg *CommentGateway) GetCommentById(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return cg.client.GetCommentById(ctx, &pb.GetCommentByIdRequest{Id: id})
+}
+
+func (cg *CommentGateway) GetCommentsByPostId(ctx context.Context, r *http.Request) (proto.Message, error) {
+	postId, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return cg.client.GetCommentsByPostId(ctx, &pb.GetCommentsByPostIdRequest{PostId: postId})
+}
+
+func (cg *CommentGateway) CreateComment(ctx context.Context, r *http.Request) (proto.Message, error) {
+	postId, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.Comment{}
+	if err := gateway.DecodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	req.PostId = postId
+
+	return cg.client.CreateComment(ctx, req)
+}
+
+func (cg *CommentGateway) ReplyComment(ctx context.Context, r *http.Request) (proto.Message, error) {
+	commentId, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.Comment{}
+	if err := gateway.DecodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	req.CommentId = commentId
+
+	return cg.client.ReplyComment(ctx, req)
+}
+
+func (cg *CommentGateway) DeleteComment(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return cg.client.DeleteComment(ctx, &pb.GetCommentByIdRequest{Id: id})
+}
+
+func (cg *CommentGateway) RestoreComment(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return cg.client.RestoreComment(ctx, &pb.GetCommentByIdRequest{Id: id})
+}
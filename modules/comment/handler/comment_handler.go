@@ -2,7 +2,7 @@ package handler
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"tracerstudy-post-service/common/config"
 	"tracerstudy-post-service/common/errors"
@@ -10,8 +10,6 @@ import (
 	"tracerstudy-post-service/modules/comment/service"
 	"tracerstudy-post-service/pb"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -31,13 +29,8 @@ func NewCommentHandler(config config.Config, commentService service.CommentServi
 func (ch *CommentHandler) GetAllComments(ctx context.Context, req *emptypb.Empty) (*pb.GetAllCommentsResponse, error) {
 	comments, err := ch.commentSvc.FindAll(ctx, req)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - GetAllComments] Error while get all comments:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetAllCommentsResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - GetAllComments] Error while get all comments", "error", err)
+		return nil, err
 	}
 
 	var commentArr []*pb.Comment
@@ -56,13 +49,8 @@ func (ch *CommentHandler) GetAllComments(ctx context.Context, req *emptypb.Empty
 func (ch *CommentHandler) GetCommentsByPostId(ctx context.Context, req *pb.GetCommentsByPostIdRequest) (*pb.GetAllCommentsResponse, error) {
 	comments, err := ch.commentSvc.FindCommentsByPostId(ctx, req.GetPostId())
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - GetCommentsByPostId] Error while get comments by post id:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetAllCommentsResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - GetCommentsByPostId] Error while get comments by post id", "error", err)
+		return nil, err
 	}
 
 	var commentArr []*pb.Comment
@@ -81,21 +69,8 @@ func (ch *CommentHandler) GetCommentsByPostId(ctx context.Context, req *pb.GetCo
 func (ch *CommentHandler) GetCommentById(ctx context.Context, req *pb.GetCommentByIdRequest) (*pb.GetCommentResponse, error) {
 	comment, err := ch.commentSvc.FindById(ctx, req.GetId())
 	if err != nil {
-		if comment == nil {
-			log.Println("WARNING: [CommentHandler - GetCommentById] Resource comment not found for id:", req.GetId())
-			// return nil, status.Errorf(codes.NotFound, "comment not found")
-			return &pb.GetCommentResponse{
-				Code:    uint32(http.StatusNotFound),
-				Message: "comment not found",
-			}, status.Errorf(codes.NotFound, "comment not found")
-		}
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - GetCommentById] Internal server error:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetCommentResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - GetCommentById] Error while find comment by id", "error", err)
+		return nil, err
 	}
 
 	commentProto := entity.ConvertEntityToProto(comment)
@@ -110,13 +85,8 @@ func (ch *CommentHandler) GetCommentById(ctx context.Context, req *pb.GetComment
 func (ch *CommentHandler) CreateComment(ctx context.Context, req *pb.Comment) (*pb.GetCommentResponse, error) {
 	comment, err := ch.commentSvc.Create(ctx, req.GetPostId(), 0, req.GetName(), req.GetContent(), 0)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - CreateComment] Error while create comment:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetCommentResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - CreateComment] Error while create comment", "error", err)
+		return nil, err
 	}
 
 	commentProto := entity.ConvertEntityToProto(comment)
@@ -132,32 +102,17 @@ func (ch *CommentHandler) ReplyComment(ctx context.Context, req *pb.Comment) (*p
 	// get parent comment
 	parentComment, err := ch.commentSvc.FindById(ctx, req.GetCommentId())
 	if err != nil {
-		if parentComment == nil {
-			log.Println("WARNING: [CommentHandler - ReplyComment] Resource parent comment not found for id:", req.GetCommentId())
-			// return nil, status.Errorf(codes.NotFound, "parent comment not found")
-			return &pb.GetCommentResponse{
-				Code:    uint32(http.StatusNotFound),
-				Message: "parent comment not found",
-			}, status.Errorf(codes.NotFound, "parent comment not found")
+		slog.ErrorContext(ctx, "[CommentHandler - ReplyComment] Error while find parent comment", "error", err)
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, errors.NotFound(errors.ReasonParentCommentNotFound, err, "parent comment %d not found", req.GetCommentId())
 		}
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - ReplyComment] Internal server error:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetCommentResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		return nil, err
 	}
 
 	comment, err := ch.commentSvc.Create(ctx, parentComment.PostId, parentComment.Id, req.GetName(), req.GetContent(), parentComment.Level+1)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - ReplyComment] Error while reply comment:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetCommentResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - ReplyComment] Error while reply comment", "error", err)
+		return nil, err
 	}
 
 	commentProto := entity.ConvertEntityToProto(comment)
@@ -172,13 +127,8 @@ func (ch *CommentHandler) ReplyComment(ctx context.Context, req *pb.Comment) (*p
 func (ch *CommentHandler) DeleteComment(ctx context.Context, req *pb.GetCommentByIdRequest) (*pb.DeleteCommentResponse, error) {
 	err := ch.commentSvc.Delete(ctx, req.GetId())
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentHandler - DeleteComment] Error while delete comment:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.DeleteCommentResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[CommentHandler - DeleteComment] Error while delete comment", "error", err)
+		return nil, err
 	}
 
 	return &pb.DeleteCommentResponse{
@@ -186,3 +136,19 @@ func (ch *CommentHandler) DeleteComment(ctx context.Context, req *pb.GetCommentB
 		Message: "delete comment success",
 	}, nil
 }
+
+func (ch *CommentHandler) RestoreComment(ctx context.Context, req *pb.GetCommentByIdRequest) (*pb.GetCommentResponse, error) {
+	comment, err := ch.commentSvc.Restore(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[CommentHandler - RestoreComment] Error while restore comment", "error", err)
+		return nil, err
+	}
+
+	commentProto := entity.ConvertEntityToProto(comment)
+
+	return &pb.GetCommentResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "restore comment success",
+		Data:    commentProto,
+	}, nil
+}
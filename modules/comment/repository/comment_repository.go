@@ -3,12 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"strconv"
+	commonErrors "tracerstudy-post-service/common/errors"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/tracing"
 	"tracerstudy-post-service/modules/comment/entity"
 
-	"go.opencensus.io/trace"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 )
 
@@ -26,73 +27,127 @@ type CommentRepositoryUseCase interface {
 	FindAll(ctx context.Context, req any) ([]*entity.Comment, error)
 	FindCommentsByPostId(ctx context.Context, postId uint64) ([]*entity.Comment, error)
 	FindById(ctx context.Context, id uint64) (*entity.Comment, error)
+	FindDeletedById(ctx context.Context, id uint64) (*entity.Comment, error)
+	Exists(ctx context.Context, id uint64) (bool, error)
 	Create(ctx context.Context, req *entity.Comment) (*entity.Comment, error)
 	Delete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, comment *entity.Comment) (*entity.Comment, error)
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 func (c *CommentRepository) FindAll(ctx context.Context, req any) ([]*entity.Comment, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "CommentRepository - FindAll")
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - FindAll")
 	defer span.End()
 
 	var comment []*entity.Comment
-	if err := c.db.Debug().WithContext(ctxSpan).Order("created_at desc").Find(&comment).Error; err != nil {
-		log.Println("ERROR: [CommentRepository - FindAll] Internal server error:", err)
-		return nil, err
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Order("created_at desc").Find(&comment).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - FindAll] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return comment, nil
 }
 
 func (c *CommentRepository) FindCommentsByPostId(ctx context.Context, postId uint64) ([]*entity.Comment, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "CommentRepository - FindCommentsByPostId")
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - FindCommentsByPostId")
 	defer span.End()
 
 	var comment []*entity.Comment
-	if err := c.db.Debug().WithContext(ctxSpan).Where("post_id = ?", postId).Order("created_at desc").Find(&comment).Error; err != nil {
-		log.Println("ERROR: [CommentRepository - FindCommentsByPostId] Internal server error:", err)
-		return nil, err
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Where("post_id = ?", postId).Order("created_at desc").Find(&comment).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - FindCommentsByPostId] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return comment, nil
 }
 
 func (c *CommentRepository) FindById(ctx context.Context, id uint64) (*entity.Comment, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "CommentRepository - FindById")
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - FindById")
 	defer span.End()
 
 	var comment entity.Comment
-	if err := c.db.Debug().WithContext(ctxSpan).Where("id = ?", id).First(&comment).Error; err != nil {
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Where("id = ?", id).First(&comment).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("WARNING: [CommentRepository - FindById] Record not found for id", id)
-			return nil, status.Errorf(codes.NotFound, "record not found for id %d", id)
+			slog.WarnContext(ctx, "[CommentRepository - FindById] Record not found", "id", id)
+			return nil, commonErrors.NotFound(commonErrors.ReasonCommentNotFound, err, "comment %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
 		}
-		log.Println("ERROR: [CommentRepository - FindById] Internal server error:", err)
-		return nil, err
+		slog.ErrorContext(ctx, "[CommentRepository - FindById] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return &comment, nil
 }
 
+// FindDeletedById finds a soft deleted comment, comments that were not deleted are not found.
+func (c *CommentRepository) FindDeletedById(ctx context.Context, id uint64) (*entity.Comment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - FindDeletedById")
+	defer span.End()
+
+	var comment entity.Comment
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.WarnContext(ctx, "[CommentRepository - FindDeletedById] Record not found", "id", id)
+			return nil, commonErrors.NotFound(commonErrors.ReasonCommentNotFound, err, "deleted comment %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
+		}
+		slog.ErrorContext(ctx, "[CommentRepository - FindDeletedById] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return &comment, nil
+}
+
+func (c *CommentRepository) Exists(ctx context.Context, id uint64) (bool, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - Exists")
+	defer span.End()
+
+	var count int64
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Model(&entity.Comment{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - Exists] Internal server error", "error", err)
+		return false, gormConn.DatabaseError(err)
+	}
+
+	return count > 0, nil
+}
+
 func (c *CommentRepository) Create(ctx context.Context, req *entity.Comment) (*entity.Comment, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "CommentRepository - Create")
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - Create")
 	defer span.End()
 
-	if err := c.db.Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
-		log.Println("ERROR: [CommentRepository - Create] Internal server error:", err)
-		return nil, err
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Create(req).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - Create] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return req, nil
 }
 
 func (c *CommentRepository) Delete(ctx context.Context, id uint64) error {
-	ctxSpan, span := trace.StartSpan(ctx, "CommentRepository - Delete")
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - Delete")
 	defer span.End()
 
-	if err := c.db.Debug().WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Comment{}).Error; err != nil {
-		log.Println("ERROR: [CommentRepository - Delete] Internal server error:", err)
-		return err
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Comment{}).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - Delete] Internal server error", "error", err)
+		return gormConn.DatabaseError(err)
 	}
 
 	return nil
 }
+
+func (c *CommentRepository) Restore(ctx context.Context, comment *entity.Comment) (*entity.Comment, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "CommentRepository - Restore")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, c.db).WithContext(ctxSpan).Unscoped().Model(comment).Update("deleted_at", nil).Error; err != nil {
+		slog.ErrorContext(ctx, "[CommentRepository - Restore] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+	comment.DeletedAt = gorm.DeletedAt{}
+
+	return comment, nil
+}
+
+// Transaction runs fn in a database transaction, the repositories it calls with its ctx
+// write in the same transaction.
+func (c *CommentRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return gormConn.Transaction(ctx, c.db, fn)
+}
@@ -2,10 +2,11 @@ package service
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 	"tracerstudy-post-service/common/config"
-	"tracerstudy-post-service/common/errors"
+	auditEntity "tracerstudy-post-service/modules/audit/entity"
+	auditService "tracerstudy-post-service/modules/audit/service"
 	"tracerstudy-post-service/modules/comment/entity"
 	"tracerstudy-post-service/modules/comment/repository"
 )
@@ -13,12 +14,14 @@ import (
 type CommentService struct {
 	cfg               config.Config
 	commentRepository repository.CommentRepositoryUseCase
+	auditSvc          auditService.AuditServiceUseCase
 }
 
-func NewCommentService(cfg config.Config, commentRepository repository.CommentRepositoryUseCase) *CommentService {
+func NewCommentService(cfg config.Config, commentRepository repository.CommentRepositoryUseCase, auditSvc auditService.AuditServiceUseCase) *CommentService {
 	return &CommentService{
 		cfg:               cfg,
 		commentRepository: commentRepository,
+		auditSvc:          auditSvc,
 	}
 }
 
@@ -28,13 +31,13 @@ type CommentServiceUseCase interface {
 	FindById(ctx context.Context, id uint64) (*entity.Comment, error)
 	Create(ctx context.Context, postId, commentId uint64, name, content string, level uint32) (*entity.Comment, error)
 	Delete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, id uint64) (*entity.Comment, error)
 }
 
 func (svc *CommentService) FindAll(ctx context.Context, req any) ([]*entity.Comment, error) {
 	res, err := svc.commentRepository.FindAll(ctx, req)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentService - FindAll] Error while find all comment:", parseError.Message)
+		slog.ErrorContext(ctx, "[CommentService - FindAll] Error while find all comment", "error", err)
 		return nil, err
 	}
 
@@ -44,8 +47,7 @@ func (svc *CommentService) FindAll(ctx context.Context, req any) ([]*entity.Comm
 func (svc *CommentService) FindCommentsByPostId(ctx context.Context, postId uint64) ([]*entity.Comment, error) {
 	res, err := svc.commentRepository.FindCommentsByPostId(ctx, postId)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentService - FindCommentsByPostId] Error while find comments by post id:", parseError.Message)
+		slog.ErrorContext(ctx, "[CommentService - FindCommentsByPostId] Error while find comments by post id", "error", err)
 		return nil, err
 	}
 
@@ -55,8 +57,7 @@ func (svc *CommentService) FindCommentsByPostId(ctx context.Context, postId uint
 func (svc *CommentService) FindById(ctx context.Context, id uint64) (*entity.Comment, error) {
 	res, err := svc.commentRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentService - FindById] Error while find comment by id:", parseError.Message)
+		slog.ErrorContext(ctx, "[CommentService - FindById] Error while find comment by id", "error", err)
 		return nil, err
 	}
 
@@ -74,10 +75,18 @@ func (svc *CommentService) Create(ctx context.Context, postId, commentId uint64,
 		UpdatedAt: time.Now(),
 	}
 
-	res, err := svc.commentRepository.Create(ctx, comment)
+	var res *entity.Comment
+	err := svc.commentRepository.Transaction(ctx, func(ctx context.Context) error {
+		created, err := svc.commentRepository.Create(ctx, comment)
+		if err != nil {
+			slog.ErrorContext(ctx, "[CommentService - Create] Error while create comment", "error", err)
+			return err
+		}
+		res = created
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourceComment, res.Id, auditEntity.ActionCreate, nil, res)
+	})
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentService - Create] Error while create comment:", parseError.Message)
 		return nil, err
 	}
 
@@ -85,12 +94,46 @@ func (svc *CommentService) Create(ctx context.Context, postId, commentId uint64,
 }
 
 func (svc *CommentService) Delete(ctx context.Context, id uint64) error {
-	err := svc.commentRepository.Delete(ctx, id)
+	comment, err := svc.commentRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [CommentService - Delete] Error while delete comment:", parseError.Message)
+		slog.ErrorContext(ctx, "[CommentService - Delete] Error while find comment by id", "error", err)
 		return err
 	}
 
-	return nil
+	return svc.commentRepository.Transaction(ctx, func(ctx context.Context) error {
+		if err := svc.commentRepository.Delete(ctx, id); err != nil {
+			slog.ErrorContext(ctx, "[CommentService - Delete] Error while delete comment", "error", err)
+			return err
+		}
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourceComment, id, auditEntity.ActionDelete, comment, nil)
+	})
+}
+
+func (svc *CommentService) Restore(ctx context.Context, id uint64) (*entity.Comment, error) {
+	comment, err := svc.commentRepository.FindDeletedById(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[CommentService - Restore] Error while find deleted comment by id", "error", err)
+		return nil, err
+	}
+
+	// the repository clears deleted_at of comment
+	before := *comment
+
+	var res *entity.Comment
+	err = svc.commentRepository.Transaction(ctx, func(ctx context.Context) error {
+		restored, err := svc.commentRepository.Restore(ctx, comment)
+		if err != nil {
+			slog.ErrorContext(ctx, "[CommentService - Restore] Error while restore comment", "error", err)
+			return err
+		}
+		res = restored
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourceComment, id, auditEntity.ActionRestore, &before, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
@@ -0,0 +1,20 @@
+package builder
+
+import (
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+	"tracerstudy-post-service/modules/media/handler"
+	"tracerstudy-post-service/modules/media/repository"
+	"tracerstudy-post-service/modules/media/service"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+func BuildMediaHandler(cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn, store storage.Storage, mediaSigner *signer.Signer) *handler.MediaHandler {
+	mediaRepo := repository.NewMediaRepository(db)
+	mediaSvc := service.NewMediaService(cfg, mediaRepo, store, mediaSigner)
+
+	return handler.NewMediaHandler(cfg, mediaSvc)
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+	"tracerstudy-post-service/pb"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MediaTableName = "media"
+
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+type Media struct {
+	Id          uint64         `json:"id"`
+	FileName    string         `json:"file_name"`
+	Path        string         `json:"path"`
+	ContentType string         `json:"content_type"`
+	Size        uint64         `json:"size"`
+	Visibility  string         `json:"visibility"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (m *Media) TableName() string {
+	return MediaTableName
+}
+
+func (m *Media) IsPrivate() bool {
+	return m.Visibility == VisibilityPrivate
+}
+
+func ConvertEntityToProto(m *Media) *pb.Media {
+	return &pb.Media{
+		Id:          m.Id,
+		FileName:    m.FileName,
+		Path:        m.Path,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Visibility:  m.Visibility,
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   m.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/media/entity"
+	"tracerstudy-post-service/modules/media/service"
+	"tracerstudy-post-service/pb"
+)
+
+type MediaHandler struct {
+	pb.UnimplementedMediaServiceServer
+	config   config.Config
+	mediaSvc service.MediaServiceUseCase
+}
+
+func NewMediaHandler(config config.Config, mediaService service.MediaServiceUseCase) *MediaHandler {
+	return &MediaHandler{
+		config:   config,
+		mediaSvc: mediaService,
+	}
+}
+
+func (mh *MediaHandler) UploadMedia(ctx context.Context, req *pb.UploadMediaRequest) (*pb.GetMediaResponse, error) {
+	media, err := mh.mediaSvc.Upload(ctx, req.GetFileName(), req.GetFileBuffer(), req.GetVisibility())
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - UploadMedia] Error while upload media", "error", err)
+		return nil, err
+	}
+
+	return &pb.GetMediaResponse{
+		Code:    uint32(http.StatusCreated),
+		Message: "upload media success",
+		Data:    entity.ConvertEntityToProto(media),
+	}, nil
+}
+
+func (mh *MediaHandler) GetMediaById(ctx context.Context, req *pb.GetMediaByIdRequest) (*pb.GetMediaResponse, error) {
+	media, err := mh.mediaSvc.FindById(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - GetMediaById] Error while find media by id", "error", err)
+		return nil, err
+	}
+
+	return &pb.GetMediaResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "get media success",
+		Data:    entity.ConvertEntityToProto(media),
+	}, nil
+}
+
+func (mh *MediaHandler) DeleteMedia(ctx context.Context, req *pb.GetMediaByIdRequest) (*pb.DeleteMediaResponse, error) {
+	media, err := mh.mediaSvc.FindById(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - DeleteMedia] Error while find media by id", "error", err)
+		return nil, err
+	}
+
+	err = mh.mediaSvc.Delete(ctx, media)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - DeleteMedia] Error while delete media", "error", err)
+		return nil, err
+	}
+
+	return &pb.DeleteMediaResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "delete media success",
+	}, nil
+}
+
+func (mh *MediaHandler) GetSignedMediaUrl(ctx context.Context, req *pb.GetMediaByIdRequest) (*pb.GetSignedMediaUrlResponse, error) {
+	media, err := mh.mediaSvc.FindById(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - GetSignedMediaUrl] Error while find media by id", "error", err)
+		return nil, err
+	}
+
+	url, expiresAt, err := mh.mediaSvc.SignedUrl(ctx, media)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaHandler - GetSignedMediaUrl] Error while sign media url", "error", err)
+		return nil, err
+	}
+
+	res := &pb.GetSignedMediaUrlResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "get signed media url success",
+		Url:     url,
+	}
+	if !expiresAt.IsZero() {
+		res.ExpiresAt = expiresAt.Format(time.RFC3339)
+	}
+
+	return res, nil
+}
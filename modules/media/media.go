@@ -0,0 +1,24 @@
+package media
+
+import (
+	"tracerstudy-post-service/common/config"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+	"tracerstudy-post-service/modules/media/builder"
+	"tracerstudy-post-service/modules/media/entity"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn, store storage.Storage, mediaSigner *signer.Signer) {
+	media := builder.BuildMediaHandler(cfg, db, grpcConn, store, mediaSigner)
+	pb.RegisterMediaServiceServer(server, media)
+}
+
+// Migrate creates the media table.
+func Migrate(db *gorm.DB) error {
+	return gormConn.Migrate(db, &entity.Media{})
+}
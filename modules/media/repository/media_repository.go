@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	commonErrors "tracerstudy-post-service/common/errors"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/tracing"
+	"tracerstudy-post-service/modules/media/entity"
+
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{
+		db: db,
+	}
+}
+
+type MediaRepositoryUseCase interface {
+	FindAll(ctx context.Context) ([]*entity.Media, error)
+	FindById(ctx context.Context, id uint64) (*entity.Media, error)
+	Create(ctx context.Context, req *entity.Media) (*entity.Media, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+func (m *MediaRepository) FindAll(ctx context.Context) ([]*entity.Media, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "MediaRepository - FindAll")
+	defer span.End()
+
+	var media []*entity.Media
+	if err := m.db.WithContext(ctxSpan).Order("created_at desc").Find(&media).Error; err != nil {
+		slog.ErrorContext(ctx, "[MediaRepository - FindAll] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return media, nil
+}
+
+func (m *MediaRepository) FindById(ctx context.Context, id uint64) (*entity.Media, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "MediaRepository - FindById")
+	defer span.End()
+
+	var media entity.Media
+	if err := m.db.WithContext(ctxSpan).Where("id = ?", id).First(&media).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.WarnContext(ctx, "[MediaRepository - FindById] Record not found", "id", id)
+			return nil, commonErrors.NotFound(commonErrors.ReasonMediaNotFound, err, "media %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
+		}
+		slog.ErrorContext(ctx, "[MediaRepository - FindById] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return &media, nil
+}
+
+func (m *MediaRepository) Create(ctx context.Context, req *entity.Media) (*entity.Media, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "MediaRepository - Create")
+	defer span.End()
+
+	if err := m.db.WithContext(ctxSpan).Create(req).Error; err != nil {
+		slog.ErrorContext(ctx, "[MediaRepository - Create] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return req, nil
+}
+
+func (m *MediaRepository) Delete(ctx context.Context, id uint64) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "MediaRepository - Delete")
+	defer span.End()
+
+	if err := m.db.WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Media{}).Error; err != nil {
+		slog.ErrorContext(ctx, "[MediaRepository - Delete] Internal server error", "error", err)
+		return gormConn.DatabaseError(err)
+	}
+
+	return nil
+}
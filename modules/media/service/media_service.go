@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/metrics"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+	"tracerstudy-post-service/modules/media/entity"
+	"tracerstudy-post-service/modules/media/repository"
+)
+
+const (
+	// PrivatePrefix is the storage key prefix of private media. The file server
+	// only serves keys under it when the request carries a valid signature.
+	PrivatePrefix = "private/"
+	publicPrefix  = "media/"
+)
+
+type MediaService struct {
+	cfg             config.Config
+	mediaRepository repository.MediaRepositoryUseCase
+	storage         storage.Storage
+	signer          *signer.Signer
+}
+
+func NewMediaService(cfg config.Config, mediaRepository repository.MediaRepositoryUseCase, store storage.Storage, urlSigner *signer.Signer) *MediaService {
+	return &MediaService{
+		cfg:             cfg,
+		mediaRepository: mediaRepository,
+		storage:         store,
+		signer:          urlSigner,
+	}
+}
+
+type MediaServiceUseCase interface {
+	FindById(ctx context.Context, id uint64) (*entity.Media, error)
+	Upload(ctx context.Context, fileName string, file []byte, visibility string) (*entity.Media, error)
+	Delete(ctx context.Context, media *entity.Media) error
+	SignedUrl(ctx context.Context, media *entity.Media) (string, time.Time, error)
+	ImageReferences(ctx context.Context) ([]string, error)
+}
+
+func (svc *MediaService) FindById(ctx context.Context, id uint64) (*entity.Media, error) {
+	res, err := svc.mediaRepository.FindById(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaService - FindById] Error while find media by id", "error", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (svc *MediaService) Upload(ctx context.Context, fileName string, file []byte, visibility string) (*entity.Media, error) {
+	if visibility == "" {
+		visibility = entity.VisibilityPublic
+	}
+	if visibility != entity.VisibilityPublic && visibility != entity.VisibilityPrivate {
+		return nil, errors.Validation(errors.ReasonInvalidVisibility, nil, "visibility must be %q or %q", entity.VisibilityPublic, entity.VisibilityPrivate)
+	}
+
+	prefix := publicPrefix
+	if visibility == entity.VisibilityPrivate {
+		prefix = PrivatePrefix
+	}
+	key := fmt.Sprintf("%s%d_%s", prefix, time.Now().UnixNano(), filepath.Base(fileName))
+
+	if err := svc.storage.Put(ctx, key, file); err != nil {
+		slog.ErrorContext(ctx, "[MediaService - Upload] Error while store media", "error", err)
+		return nil, errors.Unavailable(errors.ReasonStorageUnavailable, err, "storage is unavailable")
+	}
+	metrics.UploadBytes.WithLabelValues("media").Add(float64(len(file)))
+
+	media := &entity.Media{
+		FileName:    filepath.Base(fileName),
+		Path:        svc.cfg.PublicStoragePath + key,
+		ContentType: http.DetectContentType(file),
+		Size:        uint64(len(file)),
+		Visibility:  visibility,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	res, err := svc.mediaRepository.Create(ctx, media)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaService - Upload] Error while create media", "error", err)
+		_ = svc.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (svc *MediaService) Delete(ctx context.Context, media *entity.Media) error {
+	err := svc.mediaRepository.Delete(ctx, media.Id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaService - Delete] Error while delete media", "error", err)
+		return err
+	}
+
+	// the record is gone, a file left behind is reclaimed by the garbage collector
+	key, ok := storage.KeyFromPublicPath(svc.cfg.PublicStoragePath, media.Path)
+	if !ok {
+		slog.WarnContext(ctx, "[MediaService - Delete] Skip media file stored outside the public storage path", "path", media.Path)
+		return nil
+	}
+	if err := svc.storage.Delete(ctx, key); err != nil {
+		slog.WarnContext(ctx, "[MediaService - Delete] Error while delete media file", "error", err)
+	}
+
+	return nil
+}
+
+// SignedUrl returns an expiring signed URL for private media and the plain path for public media.
+func (svc *MediaService) SignedUrl(ctx context.Context, media *entity.Media) (string, time.Time, error) {
+	if !media.IsPrivate() {
+		return media.Path, time.Time{}, nil
+	}
+
+	expiresAt := time.Now().Add(svc.cfg.Media.SignedURLTTL)
+	return svc.signer.Sign(media.Path, expiresAt), expiresAt, nil
+}
+
+func (svc *MediaService) ImageReferences(ctx context.Context) ([]string, error) {
+	media, err := svc.mediaRepository.FindAll(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[MediaService - ImageReferences] Error while find all media", "error", err)
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(media))
+	for _, m := range media {
+		paths = append(paths, m.Path)
+	}
+
+	return paths, nil
+}
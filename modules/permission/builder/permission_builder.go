@@ -0,0 +1,24 @@
+package builder
+
+import (
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/permission/handler"
+	"tracerstudy-post-service/modules/permission/service"
+
+	"google.golang.org/grpc"
+)
+
+func BuildPermissionHandler(cfg config.Config, server *grpc.Server, policy *authorization.PolicyStore) *handler.PermissionHandler {
+	permissionSvc := service.NewPermissionService(cfg, policy, func() []string {
+		var methods []string
+		for name, info := range server.GetServiceInfo() {
+			for _, m := range info.Methods {
+				methods = append(methods, "/"+name+"/"+m.Name)
+			}
+		}
+		return methods
+	})
+
+	return handler.NewPermissionHandler(cfg, permissionSvc)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/permission/service"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type PermissionHandler struct {
+	pb.UnimplementedPermissionServiceServer
+	config        config.Config
+	permissionSvc service.PermissionServiceUseCase
+}
+
+func NewPermissionHandler(config config.Config, permissionService service.PermissionServiceUseCase) *PermissionHandler {
+	return &PermissionHandler{
+		config:        config,
+		permissionSvc: permissionService,
+	}
+}
+
+func (ph *PermissionHandler) GetMyPermissions(ctx context.Context, req *emptypb.Empty) (*pb.GetMyPermissionsResponse, error) {
+	role, methods := ph.permissionSvc.FindMine(ctx)
+
+	return &pb.GetMyPermissionsResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "get permissions success",
+		Role:    role,
+		Methods: methods,
+	}, nil
+}
@@ -0,0 +1,15 @@
+package permission
+
+import (
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/permission/builder"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/grpc"
+)
+
+func InitGrpc(server *grpc.Server, cfg config.Config, policy *authorization.PolicyStore) {
+	permission := builder.BuildPermissionHandler(cfg, server, policy)
+	pb.RegisterPermissionServiceServer(server, permission)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+)
+
+type PermissionService struct {
+	cfg     config.Config
+	policy  *authorization.PolicyStore
+	methods func() []string
+}
+
+// NewPermissionService reports permissions against the methods returned by methods, so
+// services registered after it are covered too.
+func NewPermissionService(cfg config.Config, policy *authorization.PolicyStore, methods func() []string) *PermissionService {
+	return &PermissionService{
+		cfg:     cfg,
+		policy:  policy,
+		methods: methods,
+	}
+}
+
+type PermissionServiceUseCase interface {
+	FindMine(ctx context.Context) (uint32, []string)
+}
+
+// FindMine returns the caller's role and the methods the current policy lets it call.
+func (svc *PermissionService) FindMine(ctx context.Context) (uint32, []string) {
+	var role uint32
+	if principal, ok := authorization.PrincipalFromContext(ctx); ok {
+		role = principal.Role
+	}
+
+	return role, svc.policy.Policy().Effective(role, svc.methods())
+}
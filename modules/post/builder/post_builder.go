@@ -2,6 +2,8 @@ package builder
 
 import (
 	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/storage"
+	auditBuilder "tracerstudy-post-service/modules/audit/builder"
 	"tracerstudy-post-service/modules/post/client"
 	"tracerstudy-post-service/modules/post/handler"
 	"tracerstudy-post-service/modules/post/repository"
@@ -11,11 +13,11 @@ import (
 	"gorm.io/gorm"
 )
 
-func BuildPostHandler(cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) *handler.PostHandler {
+func BuildPostHandler(cfg config.Config, db *gorm.DB, authConn *grpc.ClientConn, store storage.Storage) *handler.PostHandler {
 	postRepo := repository.NewPostRepository(db)
-	imageSvc := service.NewImageService(cfg)
-	postSvc := service.NewPostService(cfg, postRepo)
-	authSvc := client.BuildAuthServiceClient(cfg.ClientURL.Auth)
+	imageSvc := service.NewImageService(cfg, store)
+	authSvc := client.NewAuthServiceClient(authConn, cfg.ClientURL.AuthProfileCacheTTL)
+	postSvc := service.NewPostService(cfg, postRepo, auditBuilder.BuildAuditService(cfg, db), authSvc)
 
-	return handler.NewPostHandler(cfg, postSvc, imageSvc, authSvc)
+	return handler.NewPostHandler(cfg, postSvc, imageSvc)
 }
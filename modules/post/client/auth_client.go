@@ -2,30 +2,97 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/metrics"
+	"tracerstudy-post-service/common/utils"
 	"tracerstudy-post-service/pb"
-	"tracerstudy-post-service/server"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// AuthServiceClient looks up user profiles in the auth service. Callers are identified
+// from their token by the auth interceptor, so this is only needed to enrich a principal
+// with data the token does not carry.
 type AuthServiceClient struct {
 	Client pb.AuthServiceClient
+
+	profileTTL time.Duration
+	mu         sync.Mutex
+	profiles   map[string]cachedProfile
 }
 
-func BuildAuthServiceClient(url string) AuthServiceClient {
-	cc := server.InitGRPCConn(url, false, "")
+type cachedProfile struct {
+	user      *pb.User
+	expiresAt time.Time
+}
 
-	c := AuthServiceClient{
-		Client: pb.NewAuthServiceClient(cc),
+// NewAuthServiceClient uses an already dialed connection, so it can be shared with health checks.
+func NewAuthServiceClient(cc *grpc.ClientConn, profileTTL time.Duration) *AuthServiceClient {
+	return &AuthServiceClient{
+		Client:     pb.NewAuthServiceClient(cc),
+		profileTTL: profileTTL,
+		profiles:   make(map[string]cachedProfile),
 	}
-
-	return c
 }
 
 func (ac *AuthServiceClient) GetCurrentUser(ctx context.Context, req *emptypb.Empty, token string) (*pb.SingleUserResponse, error) {
 	md := metadata.New(map[string]string{"authorization": token})
 	ctxNew := metadata.NewOutgoingContext(ctx, md)
 
-	return ac.Client.GetCurrentUser(ctxNew, req)
+	start := time.Now()
+	res, err := ac.Client.GetCurrentUser(ctxNew, req)
+	metrics.AuthClientDuration.WithLabelValues("GetCurrentUser", status.Code(err).String()).Observe(time.Since(start).Seconds())
+
+	// the auth service's own messages are not passed on to our clients
+	switch status.Code(err) {
+	case codes.OK:
+		return res, nil
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return nil, errors.Unauthenticated(errors.ReasonInvalidToken, err, "access token is rejected by the auth service")
+	default:
+		return nil, errors.Unavailable(errors.ReasonAuthServiceUnavailable, err, "auth service is unavailable")
+	}
+}
+
+// GetProfile returns the profile of principal, fetched with the caller's own token and
+// cached for profileTTL.
+func (ac *AuthServiceClient) GetProfile(ctx context.Context, principal *authorization.Principal) (*pb.User, error) {
+	key := fmt.Sprintf("%d:%s", principal.Role, principal.Cred)
+	now := time.Now()
+
+	ac.mu.Lock()
+	cached, ok := ac.profiles[key]
+	ac.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.user, nil
+	}
+
+	token, err := utils.GetMetadataAuthorization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ac.GetCurrentUser(ctx, &emptypb.Empty{}, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ac.mu.Lock()
+	for k, p := range ac.profiles {
+		if !now.Before(p.expiresAt) {
+			delete(ac.profiles, k)
+		}
+	}
+	ac.profiles[key] = cachedProfile{user: res.GetData(), expiresAt: now.Add(ac.profileTTL)}
+	ac.mu.Unlock()
+
+	return res.GetData(), nil
 }
@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 	"tracerstudy-post-service/pb"
 
@@ -21,12 +24,53 @@ type Post struct {
 	Type         string         `json:"type"`
 	IsFeatured   uint32         `json:"is_featured"`
 	Visitors     uint64         `json:"visitors"`
+	AuthorId     uint64         `gorm:"index" json:"author_id"`
+	KodeProdi    string         `gorm:"index" json:"kode_prodi"`
 	CreatedBy    string         `json:"created_by"`
 	UpdatedBy    string         `json:"updated_by"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	Tags         string         `json:"tags"`
+
+	ImageVariants      ImageVariants `gorm:"type:json" json:"image_variants"`
+	ImageBlurhash      string        `json:"image_blurhash"`
+	ImageDominantColor string        `json:"image_dominant_color"`
+}
+
+type ImageVariant struct {
+	Path     string `json:"path"`
+	WebpPath string `json:"webp_path"`
+	Width    uint32 `json:"width"`
+	Height   uint32 `json:"height"`
+}
+
+// ImageVariants maps a variant name (thumbnail, medium, large) to its resized files.
+type ImageVariants map[string]*ImageVariant
+
+func (v ImageVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
+
+func (v *ImageVariants) Scan(value any) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("unsupported type %T for image variants", value)
+	}
+
+	return json.Unmarshal(data, v)
 }
 
 func (p *Post) TableName() string {
@@ -44,10 +88,34 @@ func ConvertEntityToProto(p *Post) *pb.Post {
 		Type:         p.Type,
 		IsFeatured:   p.IsFeatured,
 		Visitors:     p.Visitors,
+		AuthorId:     p.AuthorId,
+		KodeProdi:    p.KodeProdi,
 		CreatedBy:    p.CreatedBy,
 		UpdatedBy:    p.UpdatedBy,
 		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    p.UpdatedAt.Format(time.RFC3339),
 		Tags:         p.Tags,
+
+		ImageVariants:      ConvertImageVariantsToProto(p.ImageVariants),
+		ImageBlurhash:      p.ImageBlurhash,
+		ImageDominantColor: p.ImageDominantColor,
 	}
 }
+
+func ConvertImageVariantsToProto(variants ImageVariants) map[string]*pb.ImageVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	res := make(map[string]*pb.ImageVariant, len(variants))
+	for name, v := range variants {
+		res[name] = &pb.ImageVariant{
+			Path:     v.Path,
+			WebpPath: v.WebpPath,
+			Width:    v.Width,
+			Height:   v.Height,
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"tracerstudy-post-service/pb"
+	"tracerstudy-post-service/server/gateway"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const imageFormField = "image"
+
+type PostGateway struct {
+	client pb.PostServiceClient
+}
+
+func NewPostGateway(client pb.PostServiceClient) *PostGateway {
+	return &PostGateway{
+		client: client,
+	}
+}
+
+func (pg *PostGateway) Register(mux *http.ServeMux) {
+	gateway.Handle(mux, "GET /v1/posts", pg.GetAllPosts)
+	gateway.Handle(mux, "GET /v1/posts/{id}", pg.GetPostById)
+	gateway.Handle(mux, "POST /v1/posts", pg.CreatePost)
+	gateway.Handle(mux, "PUT /v1/posts/{id}", pg.UpdatePost)
+	gateway.Handle(mux, "DELETE /v1/posts/{id}", pg.DeletePost)
+	gateway.Handle(mux, "POST /v1/posts/{id}/visitors", pg.AddVisitor)
+	gateway.Handle(mux, "POST /v1/posts/{id}/restore", pg.RestorePost)
+}
+
+func (pg *PostGateway) GetAllPosts(ctx context.Context, r *http.Request) (proto.Message, error) {
+	return pg.client.GetAllPosts(ctx, &emptypb.Empty{})
+}
+
+func (pg *PostGateway) GetPostById(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.client.GetPostById(ctx, &pb.GetPostByIdRequest{Id: id})
+}
+
+func (pg *PostGateway) CreatePost(ctx context.Context, r *http.Request) (proto.Message, error) {
+	req, err := decodePostRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.client.CreatePost(ctx, req)
+}
+
+func (pg *PostGateway) UpdatePost(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := decodePostRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Id = id
+
+	return pg.client.UpdatePost(ctx, req)
+}
+
+func (pg *PostGateway) DeletePost(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.client.DeletePost(ctx, &pb.GetPostByIdRequest{Id: id})
+}
+
+func (pg *PostGateway) AddVisitor(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.client.AddVisitor(ctx, &pb.GetPostByIdRequest{Id: id})
+}
+
+// decodePostRequest accepts a JSON body (image_buffer base64 encoded) or a
+// multipart/form-data body with the image sent as the "image" file field.
+func decodePostRequest(r *http.Request) (*pb.CreatePostRequest, error) {
+	req := &pb.CreatePostRequest{}
+	if !gateway.IsMultipart(r) {
+		if err := gateway.DecodeJSON(r, req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	fileName, buf, err := gateway.ParseMultipart(r, imageFormField)
+	if err != nil {
+		return nil, err
+	}
+
+	isFeatured, err := gateway.FormUint32(r, "is_featured")
+	if err != nil {
+		return nil, err
+	}
+
+	req.Title = r.FormValue("title")
+	req.Content = r.FormValue("content")
+	req.ImageFilename = fileName
+	req.ImageBuffer = buf
+	req.ImageCaption = r.FormValue("image_caption")
+	req.Type = r.FormValue("type")
+	req.IsFeatured = isFeatured
+	req.Tags = r.FormValue("tags")
+
+	return req, nil
+}
+
+func (pg *PostGateway) RestorePost(ctx context.Context, r *http.Request) (proto.Message, error) {
+	id, err := gateway.PathUint64(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.client.RestorePost(ctx, &pb.GetPostByIdRequest{Id: id})
+}
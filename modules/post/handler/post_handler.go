@@ -2,18 +2,13 @@ package handler
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"tracerstudy-post-service/common/config"
-	"tracerstudy-post-service/common/errors"
-	"tracerstudy-post-service/common/utils"
-	"tracerstudy-post-service/modules/post/client"
 	"tracerstudy-post-service/modules/post/entity"
 	"tracerstudy-post-service/modules/post/service"
 	"tracerstudy-post-service/pb"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -22,28 +17,21 @@ type PostHandler struct {
 	config   config.Config
 	postSvc  service.PostServiceUseCase
 	imageSvc service.ImageServiceUseCase
-	authSvc  client.AuthServiceClient
 }
 
-func NewPostHandler(config config.Config, postService service.PostServiceUseCase, imageService service.ImageServiceUseCase, authService client.AuthServiceClient) *PostHandler {
+func NewPostHandler(config config.Config, postService service.PostServiceUseCase, imageService service.ImageServiceUseCase) *PostHandler {
 	return &PostHandler{
 		config:   config,
 		postSvc:  postService,
 		imageSvc: imageService,
-		authSvc:  authService,
 	}
 }
 
 func (ph *PostHandler) GetAllPosts(ctx context.Context, req *emptypb.Empty) (*pb.GetAllPostsResponse, error) {
 	post, err := ph.postSvc.FindAll(ctx, req)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - GetAllPost] Error while get all post: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetAllPostsResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - GetAllPost] Error while get all post", "error", err)
+		return nil, err
 	}
 
 	var postArr []*pb.Post
@@ -62,21 +50,8 @@ func (ph *PostHandler) GetAllPosts(ctx context.Context, req *emptypb.Empty) (*pb
 func (ph *PostHandler) GetPostById(ctx context.Context, req *pb.GetPostByIdRequest) (*pb.GetPostResponse, error) {
 	post, err := ph.postSvc.FindById(ctx, req.GetId())
 	if err != nil {
-		if post == nil {
-			log.Println("WARNING: [PostHandler - GetPostById] Resource post not found for id:", req.GetId())
-			// return nil, status.Errorf(codes.NotFound, "post not found")
-			return &pb.GetPostResponse{
-				Code:    uint32(http.StatusNotFound),
-				Message: "post not found",
-			}, status.Errorf(codes.NotFound, "post not found")
-		}
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - GetPostById] Internal server error:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - GetPostById] Error while find post by id", "error", err)
+		return nil, err
 	}
 
 	postProto := entity.ConvertEntityToProto(post)
@@ -89,36 +64,19 @@ func (ph *PostHandler) GetPostById(ctx context.Context, req *pb.GetPostByIdReque
 }
 
 func (ph *PostHandler) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.GetPostResponse, error) {
-	image, err := ph.imageSvc.UploadImage(ctx, req.GetImageFilename(), req.GetImageBuffer())
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - CreatePost] Error while upload image: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
-	}
-
-	accessToken, err := utils.GetMetadataAuthorization(ctx)
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - CreatePost] Error while get metadata authorization:", parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
-	}
-
-	currentUser, err := ph.authSvc.GetCurrentUser(ctx, &emptypb.Empty{}, accessToken)
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - CreatePost] Error while get current user: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+	// the file cleanup must finish even when the client is gone
+	imageTx := ph.imageSvc.Begin()
+	defer imageTx.Rollback(context.WithoutCancel(ctx))
+
+	// the image is optional, a post without one keeps the image columns empty
+	image := &service.UploadedImage{}
+	if len(req.GetImageBuffer()) > 0 {
+		var err error
+		image, err = imageTx.UploadImage(ctx, req.GetImageBuffer())
+		if err != nil {
+			slog.ErrorContext(ctx, "[PostHandler - CreatePost] Error while upload image", "error", err)
+			return nil, err
+		}
 	}
 
 	post, err := ph.postSvc.Create(
@@ -129,17 +87,15 @@ func (ph *PostHandler) CreatePost(ctx context.Context, req *pb.CreatePostRequest
 		req.GetImageCaption(),
 		req.GetType(),
 		req.GetIsFeatured(),
-		currentUser.GetData().Username,
 		req.GetTags(),
 	)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - CreatePost] Error while create post: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - CreatePost] Error while create post", "error", err)
+		return nil, err
+	}
+
+	if err := imageTx.Commit(context.WithoutCancel(ctx)); err != nil {
+		slog.WarnContext(ctx, "[PostHandler - CreatePost] Error while commit image transaction", "error", err)
 	}
 
 	postProto := entity.ConvertEntityToProto(post)
@@ -154,87 +110,51 @@ func (ph *PostHandler) CreatePost(ctx context.Context, req *pb.CreatePostRequest
 func (ph *PostHandler) UpdatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.GetPostResponse, error) {
 	post, err := ph.postSvc.FindById(ctx, req.GetId())
 	if err != nil {
-		if post == nil {
-			log.Println("WARNING: [PostHandler - GetPostById] Resource post not found for id:", req.GetId())
-			// return nil, status.Errorf(codes.NotFound, "post not found")
-			return &pb.GetPostResponse{
-				Code:    uint32(http.StatusNotFound),
-				Message: "post not found",
-			}, status.Errorf(codes.NotFound, "post not found")
-		}
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - GetPostById] Internal server error:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
-	}
-
-	image, err := ph.imageSvc.UploadImage(ctx, req.GetImageFilename(), req.GetImageBuffer())
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - UpdatePost] Error while upload image: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - UpdatePost] Error while find post by id", "error", err)
+		return nil, err
 	}
 
-	err = ph.imageSvc.DeleteImage(ctx, post.ImagePath)
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - UpdatePost] Error while delete image: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
-	}
-
-	accessToken, err := utils.GetMetadataAuthorization(ctx)
-	if err != nil {
-		log.Println("ERROR: [PostHandler - CreatePost] Error while getting metadata authorization:", err)
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - CreatePost] Error while get metadata authorization:", parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
-	}
-
-	currentUser, err := ph.authSvc.GetCurrentUser(ctx, &emptypb.Empty{}, accessToken)
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - UpdatePost] Error while get current user: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+	// checked before touching images, the service checks again on write
+	if err := ph.postSvc.CanManage(ctx, post); err != nil {
+		return nil, err
 	}
 
 	postDataUpdate := &entity.Post{
 		Title:        req.GetTitle(),
 		Content:      req.GetContent(),
-		ImagePath:    image,
 		ImageCaption: req.GetImageCaption(),
 		Type:         req.GetType(),
 		IsFeatured:   req.GetIsFeatured(),
-		UpdatedBy:    currentUser.GetData().Username,
 		Tags:         req.GetTags(),
 	}
 
+	imageTx := ph.imageSvc.Begin()
+	defer imageTx.Rollback(context.WithoutCancel(ctx))
+
+	// the current image is kept unless a new one is sent
+	if len(req.GetImageBuffer()) > 0 {
+		image, err := imageTx.UploadImage(ctx, req.GetImageBuffer())
+		if err != nil {
+			slog.ErrorContext(ctx, "[PostHandler - UpdatePost] Error while upload image", "error", err)
+			return nil, err
+		}
+
+		postDataUpdate.ImagePath = image.Path
+		postDataUpdate.ImageVariants = image.Variants
+		postDataUpdate.ImageBlurhash = image.Blurhash
+		postDataUpdate.ImageDominantColor = image.DominantColor
+
+		imageTx.DeleteImage(post.ImagePath, post.ImageVariants)
+	}
+
 	post, err = ph.postSvc.Update(ctx, req.GetId(), postDataUpdate)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - UpdatePost] Error while update post: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - UpdatePost] Error while update post", "error", err)
+		return nil, err
+	}
+
+	if err := imageTx.Commit(context.WithoutCancel(ctx)); err != nil {
+		slog.WarnContext(ctx, "[PostHandler - UpdatePost] Error while commit image transaction", "error", err)
 	}
 
 	postProto := entity.ConvertEntityToProto(post)
@@ -249,43 +169,20 @@ func (ph *PostHandler) UpdatePost(ctx context.Context, req *pb.CreatePostRequest
 func (ph *PostHandler) DeletePost(ctx context.Context, req *pb.GetPostByIdRequest) (*pb.DeletePostResponse, error) {
 	post, err := ph.postSvc.FindById(ctx, req.GetId())
 	if err != nil {
-		if post == nil {
-			log.Println("WARNING: [PostHandler - GetPostById] Resource post not found for id:", req.GetId())
-			// return nil, status.Errorf(codes.NotFound, "post not found")
-			return &pb.DeletePostResponse{
-				Code:    uint32(http.StatusNotFound),
-				Message: "post not found",
-			}, status.Errorf(codes.NotFound, "post not found")
-		}
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - GetPostById] Internal server error:", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.DeletePostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - DeletePost] Error while find post by id", "error", err)
+		return nil, err
 	}
 
-	err = ph.imageSvc.DeleteImage(ctx, post.ImagePath)
-	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - DeletePost] Error while delete image: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.DeletePostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+	if err := ph.postSvc.CanManage(ctx, post); err != nil {
+		return nil, err
 	}
 
+	// the post is only soft deleted, its images stay until the row is purged and the
+	// garbage collector finds them unreferenced
 	err = ph.postSvc.Delete(ctx, req.GetId())
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - DeletePost] Error while delete post: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.DeletePostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - DeletePost] Error while delete post", "error", err)
+		return nil, err
 	}
 
 	return &pb.DeletePostResponse{
@@ -297,13 +194,8 @@ func (ph *PostHandler) DeletePost(ctx context.Context, req *pb.GetPostByIdReques
 func (ph *PostHandler) AddVisitor(ctx context.Context, req *pb.GetPostByIdRequest) (*pb.GetPostResponse, error) {
 	post, err := ph.postSvc.IncrementVisitor(ctx, req.GetId())
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostHandler - AddVisitor] Error while increment visitor: ", parseError.Message)
-		// return nil, status.Errorf(parseError.Code, parseError.Message)
-		return &pb.GetPostResponse{
-			Code:    uint32(http.StatusInternalServerError),
-			Message: parseError.Message,
-		}, status.Errorf(parseError.Code, parseError.Message)
+		slog.ErrorContext(ctx, "[PostHandler - AddVisitor] Error while increment visitor", "error", err)
+		return nil, err
 	}
 
 	postProto := entity.ConvertEntityToProto(post)
@@ -314,3 +206,19 @@ func (ph *PostHandler) AddVisitor(ctx context.Context, req *pb.GetPostByIdReques
 		Data:    postProto,
 	}, nil
 }
+
+func (ph *PostHandler) RestorePost(ctx context.Context, req *pb.GetPostByIdRequest) (*pb.GetPostResponse, error) {
+	post, err := ph.postSvc.Restore(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostHandler - RestorePost] Error while restore post", "error", err)
+		return nil, err
+	}
+
+	postProto := entity.ConvertEntityToProto(post)
+
+	return &pb.GetPostResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "restore post success",
+		Data:    postProto,
+	}, nil
+}
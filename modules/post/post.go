@@ -1,15 +1,30 @@
 package post
 
 import (
+	"net/http"
 	"tracerstudy-post-service/common/config"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/storage"
 	"tracerstudy-post-service/modules/post/builder"
+	"tracerstudy-post-service/modules/post/entity"
+	"tracerstudy-post-service/modules/post/gateway"
 	"tracerstudy-post-service/pb"
 
 	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
-func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, grpcConn *grpc.ClientConn) {
-	post := builder.BuildPostHandler(cfg, db, grpcConn)
+func InitGrpc(server *grpc.Server, cfg config.Config, db *gorm.DB, authConn *grpc.ClientConn, store storage.Storage) {
+	post := builder.BuildPostHandler(cfg, db, authConn, store)
 	pb.RegisterPostServiceServer(server, post)
 }
+
+// Migrate adds the post columns introduced after the table was created, such as the
+// image variants and placeholder.
+func Migrate(db *gorm.DB) error {
+	return gormConn.Migrate(db, &entity.Post{})
+}
+
+func InitRest(mux *http.ServeMux, grpcConn *grpc.ClientConn) {
+	gateway.NewPostGateway(pb.NewPostServiceClient(grpcConn)).Register(mux)
+}
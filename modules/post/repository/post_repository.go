@@ -3,13 +3,14 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"strconv"
 	"time"
+	commonErrors "tracerstudy-post-service/common/errors"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/tracing"
 	"tracerstudy-post-service/modules/post/entity"
 
-	"go.opencensus.io/trace"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 )
 
@@ -26,82 +27,199 @@ func NewPostRepository(db *gorm.DB) *PostRepository {
 type PostRepositoryUseCase interface {
 	FindAll(ctx context.Context, req any) ([]*entity.Post, error)
 	FindById(ctx context.Context, id uint64) (*entity.Post, error)
+	FindDeletedById(ctx context.Context, id uint64) (*entity.Post, error)
+	Exists(ctx context.Context, id uint64) (bool, error)
+	FindMissingPlaceholders(ctx context.Context) ([]*entity.Post, error)
+	UpdatePlaceholder(ctx context.Context, id uint64, blurhash, dominantColor string) error
+	FindAllImages(ctx context.Context, deletedSince time.Time) ([]*entity.Post, error)
 	Create(ctx context.Context, req *entity.Post) (*entity.Post, error)
 	Update(ctx context.Context, post *entity.Post, updatedFields map[string]interface{}) (*entity.Post, error)
 	Delete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, post *entity.Post) (*entity.Post, error)
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 func (p *PostRepository) FindAll(ctx context.Context, req any) ([]*entity.Post, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "PostRepository - FindAll")
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - FindAll")
 	defer span.End()
 
 	var post []*entity.Post
-	if err := p.db.Debug().WithContext(ctxSpan).Order("created_at desc").Find(&post).Error; err != nil {
-		log.Println("ERROR: [PostRepository - FindAll] Internal server error:", err)
-		return nil, err
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Order("created_at desc").Find(&post).Error; err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - FindAll] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return post, nil
 }
 
 func (p *PostRepository) FindById(ctx context.Context, id uint64) (*entity.Post, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "PostRepository - FindById")
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - FindById")
 	defer span.End()
 
 	var post entity.Post
-	if err := p.db.Debug().WithContext(ctxSpan).Where("id = ?", id).First(&post).Error; err != nil {
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Where("id = ?", id).First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("WARNING: [PostRepository - FindById] Record not found for id", id)
-			return nil, status.Errorf(codes.NotFound, "record not found for id %d", id)
+			slog.WarnContext(ctx, "[PostRepository - FindById] Record not found", "id", id)
+			return nil, commonErrors.NotFound(commonErrors.ReasonPostNotFound, err, "post %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
 		}
-		log.Println("ERROR: [PostRepository - FindById] Internal server error:", err)
-		return nil, err
+		slog.ErrorContext(ctx, "[PostRepository - FindById] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return &post, nil
 }
 
+// FindDeletedById finds a soft deleted post, posts that were not deleted are not found.
+func (p *PostRepository) FindDeletedById(ctx context.Context, id uint64) (*entity.Post, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - FindDeletedById")
+	defer span.End()
+
+	var post entity.Post
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.WarnContext(ctx, "[PostRepository - FindDeletedById] Record not found", "id", id)
+			return nil, commonErrors.NotFound(commonErrors.ReasonPostNotFound, err, "deleted post %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
+		}
+		slog.ErrorContext(ctx, "[PostRepository - FindDeletedById] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return &post, nil
+}
+
+func (p *PostRepository) Exists(ctx context.Context, id uint64) (bool, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - Exists")
+	defer span.End()
+
+	var count int64
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Model(&entity.Post{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - Exists] Internal server error", "error", err)
+		return false, gormConn.DatabaseError(err)
+	}
+
+	return count > 0, nil
+}
+
+func (p *PostRepository) FindMissingPlaceholders(ctx context.Context) ([]*entity.Post, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - FindMissingPlaceholders")
+	defer span.End()
+
+	var post []*entity.Post
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Where("image_path <> '' AND (image_blurhash IS NULL OR image_blurhash = '')").Find(&post).Error; err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - FindMissingPlaceholders] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return post, nil
+}
+
+// UpdatePlaceholder writes only the placeholder columns, updated_at and updated_by are left
+// as they are since the post itself does not change.
+func (p *PostRepository) UpdatePlaceholder(ctx context.Context, id uint64, blurhash, dominantColor string) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - UpdatePlaceholder")
+	defer span.End()
+
+	err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Model(&entity.Post{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"image_blurhash":       blurhash,
+		"image_dominant_color": dominantColor,
+	}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - UpdatePlaceholder] Internal server error", "error", err)
+		return gormConn.DatabaseError(err)
+	}
+
+	return nil
+}
+
+// FindAllImages finds the images of every post, soft deleted ones included as they can still
+// be restored. Posts deleted before deletedSince are left out, a zero time keeps them all.
+func (p *PostRepository) FindAllImages(ctx context.Context, deletedSince time.Time) ([]*entity.Post, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - FindAllImages")
+	defer span.End()
+
+	query := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Unscoped().Select("id", "image_path", "image_variants").Where("image_path <> ''")
+	if !deletedSince.IsZero() {
+		query = query.Where("deleted_at IS NULL OR deleted_at >= ?", deletedSince)
+	}
+
+	var post []*entity.Post
+	if err := query.Find(&post).Error; err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - FindAllImages] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return post, nil
+}
+
 func (p *PostRepository) Create(ctx context.Context, req *entity.Post) (*entity.Post, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "PostRepository - Create")
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - Create")
 	defer span.End()
 
-	if err := p.db.Debug().WithContext(ctxSpan).Create(req).Error; err != nil {
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Create(req).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			log.Println("WARNING: [PostRepository - Create] Record already exists")
-			return nil, status.Errorf(codes.AlreadyExists, "record already exists")
+			slog.WarnContext(ctx, "[PostRepository - Create] Record already exists")
+			return nil, commonErrors.Conflict(commonErrors.ReasonPostAlreadyExists, err, "post already exists")
 		}
-		log.Println("ERROR: [PostRepository - Create] Internal server error:", err)
-		return nil, err
+		slog.ErrorContext(ctx, "[PostRepository - Create] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return req, nil
 }
 
 func (p *PostRepository) Update(ctx context.Context, post *entity.Post, updatedFields map[string]interface{}) (*entity.Post, error) {
-	ctxSpan, span := trace.StartSpan(ctx, "PostRepository - Update")
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - Update")
 	defer span.End()
 
 	updatedFields["updated_at"] = time.Now()
-	if err := p.db.Debug().WithContext(ctxSpan).Model(&post).Updates(updatedFields).Error; err != nil {
+	columns := make([]string, 0, len(updatedFields))
+	for column := range updatedFields {
+		columns = append(columns, column)
+	}
+	// selected explicitly so zero values, such as an empty blurhash, are written too
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Model(&post).Select(columns).Updates(updatedFields).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			log.Println("WARNING: [PostRepository - Update] Record already exists")
-			return nil, status.Errorf(codes.AlreadyExists, "record already exists")
+			slog.WarnContext(ctx, "[PostRepository - Update] Record already exists")
+			return nil, commonErrors.Conflict(commonErrors.ReasonPostAlreadyExists, err, "post already exists")
 		}
-		log.Println("ERROR: [PostRepository - Update] Internal server error:", err)
-		return nil, err
+		slog.ErrorContext(ctx, "[PostRepository - Update] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
 	}
 
 	return post, nil
 }
 
 func (p *PostRepository) Delete(ctx context.Context, id uint64) error {
-	ctxSpan, span := trace.StartSpan(ctx, "PostRepository - Delete")
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - Delete")
 	defer span.End()
 
-	if err := p.db.Debug().WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Post{}).Error; err != nil {
-		log.Println("ERROR: [PostRepository - Delete] Internal server error:", err)
-		return err
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Post{}).Error; err != nil {
+		slog.ErrorContext(ctx, "[PostRepository - Delete] Internal server error", "error", err)
+		return gormConn.DatabaseError(err)
 	}
 
 	return nil
 }
+
+func (p *PostRepository) Restore(ctx context.Context, post *entity.Post) (*entity.Post, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "PostRepository - Restore")
+	defer span.End()
+
+	if err := gormConn.Conn(ctx, p.db).WithContext(ctxSpan).Unscoped().Model(post).Update("deleted_at", nil).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			slog.WarnContext(ctx, "[PostRepository - Restore] Record already exists", "id", post.Id)
+			return nil, commonErrors.Conflict(commonErrors.ReasonPostAlreadyExists, err, "post already exists")
+		}
+		slog.ErrorContext(ctx, "[PostRepository - Restore] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+	post.DeletedAt = gorm.DeletedAt{}
+
+	return post, nil
+}
+
+// Transaction runs fn in a database transaction, the repositories it calls with its ctx
+// write in the same transaction.
+func (p *PostRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return gormConn.Transaction(ctx, p.db, fn)
+}
@@ -1,43 +1,315 @@
 package service
 
 import (
+	"bytes"
 	"context"
-	"log"
-	"os"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"path/filepath"
+	"strings"
 	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/imaging"
+	"tracerstudy-post-service/common/metrics"
+	"tracerstudy-post-service/common/storage"
+	"tracerstudy-post-service/modules/post/entity"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	jpegQuality = 85
+	webpExt     = ".webp"
 )
 
+// imageVariantSizes holds the maximum width of every generated variant.
+// Variants wider than the original image are skipped, images are never upscaled.
+var imageVariantSizes = []struct {
+	Name  string
+	Width int
+}{
+	{"thumbnail", 320},
+	{"medium", 768},
+	{"large", 1280},
+}
+
 type ImageService struct {
-	cfg config.Config
+	cfg     config.Config
+	storage storage.Storage
 }
 
-func NewImageService(cfg config.Config) *ImageService {
+func NewImageService(cfg config.Config, store storage.Storage) *ImageService {
 	return &ImageService{
-		cfg: cfg,
+		cfg:     cfg,
+		storage: store,
 	}
 }
 
+type UploadedImage struct {
+	Path          string
+	Variants      entity.ImageVariants
+	Blurhash      string
+	DominantColor string
+}
+
 type ImageServiceUseCase interface {
-	UploadImage(ctx context.Context, fileName string, image []byte) (string, error)
+	UploadImage(ctx context.Context, image []byte) (*UploadedImage, error)
 	DeleteImage(ctx context.Context, image string) error
+	DeleteImageVariants(ctx context.Context, variants entity.ImageVariants) error
+	GeneratePlaceholder(ctx context.Context, image string) (string, string, error)
+	Begin() ImageTransactionUseCase
 }
 
-func (svc *ImageService) UploadImage(ctx context.Context, fileName string, image []byte) (string, error) {
-	err := os.WriteFile(svc.cfg.StoragePath+fileName, image, 0644)
+// UploadImage stores image under a new random key, so an upload never replaces a file
+// another post refers to. The extension follows the detected format, not the client's name.
+func (svc *ImageService) UploadImage(ctx context.Context, image []byte) (*UploadedImage, error) {
+	img, format, clean, err := svc.sanitizeImage(image)
+	if err != nil {
+		slog.ErrorContext(ctx, "[ImageService - UploadImage] Error while sanitize image", "error", err)
+		return nil, err
+	}
+
+	fileName := uuid.NewString() + formatExt(format)
+
+	err = svc.storage.Put(ctx, fileName, clean)
 	if err != nil {
-		log.Println("ERROR: [ImageService - UploadImage] Error while upload image:", err)
-		return "", err
+		slog.ErrorContext(ctx, "[ImageService - UploadImage] Error while upload image", "error", err)
+		return nil, errors.Unavailable(errors.ReasonStorageUnavailable, err, "storage is unavailable")
 	}
+	metrics.UploadBytes.WithLabelValues("post_image").Add(float64(len(image)))
 
-	return svc.cfg.PublicStoragePath + fileName, nil
+	variants, err := svc.generateVariants(ctx, fileName, img, format)
+	if err != nil {
+		slog.ErrorContext(ctx, "[ImageService - UploadImage] Error while generate image variants", "error", err)
+		_ = svc.storage.Delete(ctx, fileName)
+		return nil, err
+	}
+
+	blurhash, dominantColor, err := imaging.Placeholder(img)
+	if err != nil {
+		// placeholders are cosmetic, an upload never fails because of them
+		slog.WarnContext(ctx, "[ImageService - UploadImage] Error while generate image placeholder", "error", err)
+	}
+
+	return &UploadedImage{
+		Path:          svc.cfg.PublicStoragePath + fileName,
+		Variants:      variants,
+		Blurhash:      blurhash,
+		DominantColor: dominantColor,
+	}, nil
 }
 
 func (svc *ImageService) DeleteImage(ctx context.Context, image string) error {
-	err := os.Remove(image)
+	key, ok := svc.toStorageKey(image)
+	if !ok {
+		slog.WarnContext(ctx, "[ImageService - DeleteImage] Skip image stored outside the public storage path", "image", image)
+		return nil
+	}
+
+	err := svc.storage.Delete(ctx, key)
 	if err != nil {
-		log.Println("ERROR: [ImageService - DeleteImage] Error while delete image:", err)
+		slog.ErrorContext(ctx, "[ImageService - DeleteImage] Error while delete image", "error", err)
 		return err
 	}
 
 	return nil
 }
+
+// GeneratePlaceholder computes the blurhash and dominant color of an already stored image.
+func (svc *ImageService) GeneratePlaceholder(ctx context.Context, image string) (string, string, error) {
+	key, ok := svc.toStorageKey(image)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s is stored outside the public storage path", storage.ErrInvalidKey, image)
+	}
+
+	buf, err := svc.storage.Get(ctx, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "[ImageService - GeneratePlaceholder] Error while read image", "error", err)
+		return "", "", err
+	}
+
+	img, _, err := decodeImage(buf)
+	if err != nil {
+		slog.ErrorContext(ctx, "[ImageService - GeneratePlaceholder] Error while decode image", "error", err)
+		return "", "", err
+	}
+
+	return imaging.Placeholder(img)
+}
+
+func (svc *ImageService) DeleteImageVariants(ctx context.Context, variants entity.ImageVariants) error {
+	for name, v := range variants {
+		for _, path := range []string{v.Path, v.WebpPath} {
+			key, ok := svc.toStorageKey(path)
+			if !ok {
+				continue
+			}
+			err := svc.storage.Delete(ctx, key)
+			if err != nil {
+				slog.ErrorContext(ctx, "[ImageService - DeleteImageVariants] Error while delete image variant", "variant", name, "error", err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sanitizeImage applies the EXIF orientation and re-encodes the image, which drops every
+// metadata block (EXIF, XMP, ICC, comments). Artist and copyright tags are written back
+// for JPEG and PNG when Image.RetainCopyright is enabled.
+func (svc *ImageService) sanitizeImage(buf []byte) (image.Image, string, []byte, error) {
+	meta, err := imaging.ReadMetadata(buf)
+	if err != nil {
+		slog.Warn("[ImageService - sanitizeImage] Ignoring malformed exif data", "error", err)
+	}
+
+	imgConfig, format, err := image.DecodeConfig(bytes.NewReader(buf))
+	if err != nil {
+		return nil, "", nil, errors.Validation(errors.ReasonUnsupportedImage, err, "unsupported image: %v", err)
+	}
+	if maxPixels := svc.cfg.Image.MaxPixels; maxPixels > 0 && int64(imgConfig.Width)*int64(imgConfig.Height) > maxPixels {
+		return nil, "", nil, errors.Validation(errors.ReasonUnsupportedImage, nil, "image of %dx%d pixels is larger than the %d pixels allowed", imgConfig.Width, imgConfig.Height, maxPixels)
+	}
+
+	// gif carries no exif, re-encoding every frame keeps animations intact
+	if format == "gif" {
+		g, err := gif.DecodeAll(bytes.NewReader(buf))
+		if err != nil {
+			return nil, "", nil, errors.Validation(errors.ReasonUnsupportedImage, err, "unsupported image: %v", err)
+		}
+		var out bytes.Buffer
+		if err := gif.EncodeAll(&out, g); err != nil {
+			return nil, "", nil, err
+		}
+		return g.Image[0], format, out.Bytes(), nil
+	}
+
+	img, _, err := decodeImage(buf)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	img = imaging.ApplyOrientation(img, meta.Orientation)
+
+	clean, err := encodeImage(img, format)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if svc.cfg.Image.RetainCopyright {
+		clean = imaging.EmbedTiff(clean, format, imaging.BuildTiff(meta.Artist, meta.Copyright))
+	}
+
+	return img, format, clean, nil
+}
+
+func (svc *ImageService) generateVariants(ctx context.Context, fileName string, src image.Image, format string) (entity.ImageVariants, error) {
+	ext := filepath.Ext(fileName)
+	base := strings.TrimSuffix(fileName, ext)
+	bounds := src.Bounds()
+
+	variants := make(entity.ImageVariants)
+	for _, size := range imageVariantSizes {
+		if size.Width >= bounds.Dx() {
+			continue
+		}
+
+		height := bounds.Dy() * size.Width / bounds.Dx()
+		dst := image.NewNRGBA(image.Rect(0, 0, size.Width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+		variantName := base + "_" + size.Name + ext
+		if err := svc.writeImage(ctx, variantName, dst, format); err != nil {
+			svc.removeFiles(ctx, variants)
+			return nil, err
+		}
+
+		webpName := base + "_" + size.Name + webpExt
+		if err := svc.writeImage(ctx, webpName, dst, "webp"); err != nil {
+			_ = svc.storage.Delete(ctx, variantName)
+			svc.removeFiles(ctx, variants)
+			return nil, err
+		}
+
+		variants[size.Name] = &entity.ImageVariant{
+			Path:     svc.cfg.PublicStoragePath + variantName,
+			WebpPath: svc.cfg.PublicStoragePath + webpName,
+			Width:    uint32(size.Width),
+			Height:   uint32(height),
+		}
+	}
+
+	return variants, nil
+}
+
+func (svc *ImageService) writeImage(ctx context.Context, fileName string, img image.Image, format string) error {
+	buf, err := encodeImage(img, format)
+	if err != nil {
+		return err
+	}
+
+	return svc.storage.Put(ctx, fileName, buf)
+}
+
+func decodeImage(buf []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, "", errors.Validation(errors.ReasonUnsupportedImage, err, "unsupported image: %v", err)
+	}
+
+	return img, format, nil
+}
+
+func encodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		return nil, errors.Validation(errors.ReasonUnsupportedImage, nil, "unsupported image format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatExt(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+
+	return "." + format
+}
+
+func (svc *ImageService) removeFiles(ctx context.Context, variants entity.ImageVariants) {
+	for _, v := range variants {
+		for _, path := range []string{v.Path, v.WebpPath} {
+			if key, ok := svc.toStorageKey(path); ok {
+				_ = svc.storage.Delete(ctx, key)
+			}
+		}
+	}
+}
+
+// toStorageKey maps a public image path back to its storage key, paths outside
+// PublicStoragePath are not ours to touch.
+func (svc *ImageService) toStorageKey(publicPath string) (string, bool) {
+	return storage.KeyFromPublicPath(svc.cfg.PublicStoragePath, publicPath)
+}
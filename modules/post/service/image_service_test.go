@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/imaging"
+	"tracerstudy-post-service/common/storage"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+const secretMake = "SecretCamera-GPS"
+
+// cameraExif is a big-endian TIFF block like a phone writes: Make, Orientation, Artist,
+// Copyright and a GPS IFD pointer.
+func cameraExif(orientation uint16) []byte {
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+	ascii := func(s string) []byte { return append([]byte(s), 0) }
+	entries := []entry{
+		{tag: 0x010F, typ: 2, value: ascii(secretMake)},
+		{tag: 0x0112, typ: 3, count: 1, value: binary.BigEndian.AppendUint16(nil, orientation)},
+		{tag: 0x013B, typ: 2, value: ascii("Ana Lestari")},
+		{tag: 0x8298, typ: 2, value: ascii("(c) Universitas")},
+		{tag: 0x8825, typ: 4, count: 1, value: binary.BigEndian.AppendUint32(nil, 0)},
+	}
+
+	ifd := binary.BigEndian.AppendUint16(nil, uint16(len(entries)))
+	var data []byte
+	dataOffset := 8 + 2 + len(entries)*12 + 4
+	for _, e := range entries {
+		ifd = binary.BigEndian.AppendUint16(ifd, e.tag)
+		ifd = binary.BigEndian.AppendUint16(ifd, e.typ)
+		if e.typ == 2 {
+			e.count = uint32(len(e.value))
+		}
+		ifd = binary.BigEndian.AppendUint32(ifd, e.count)
+		if len(e.value) <= 4 {
+			ifd = append(ifd, append(e.value, make([]byte, 4-len(e.value))...)...)
+		} else {
+			ifd = binary.BigEndian.AppendUint32(ifd, uint32(dataOffset+len(data)))
+			data = append(data, e.value...)
+		}
+	}
+	ifd = binary.BigEndian.AppendUint32(ifd, 0)
+
+	tiff := append([]byte("MM\x00\x2A\x00\x00\x00\x08"), ifd...)
+	return append(tiff, data...)
+}
+
+func cameraImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 300; y++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 90, A: 255})
+		}
+	}
+
+	return img
+}
+
+func cameraJPEG(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, cameraImage(), nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+
+	return imaging.EmbedTiff(buf.Bytes(), "jpeg", cameraExif(orientation))
+}
+
+func cameraPNG(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cameraImage()); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	return imaging.EmbedTiff(buf.Bytes(), "png", cameraExif(orientation))
+}
+
+// cameraWebP turns a lossless WebP into the extended format with an EXIF chunk.
+func cameraWebP(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, cameraImage(), nil); err != nil {
+		t.Fatalf("nativewebp.Encode() error = %v", err)
+	}
+	// the simple format is RIFF, size, WEBP and a single VP8L chunk
+	vp8l := buf.Bytes()[12:]
+
+	chunk := func(name string, data []byte) []byte {
+		out := binary.LittleEndian.AppendUint32([]byte(name), uint32(len(data)))
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	// VP8X flags with the EXIF bit, then the canvas size minus one in 24 bits each
+	vp8x := []byte{0x08, 0, 0, 0, 0x8F, 0x01, 0x00, 0x2B, 0x01, 0x00}
+
+	body := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	body = append(body, vp8l...)
+	body = append(body, chunk("EXIF", cameraExif(orientation))...)
+
+	out := binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body)))
+	return append(out, body...)
+}
+
+func uploadAndRead(t *testing.T, cfg config.Config, buf []byte) []byte {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	svc := NewImageService(cfg, store)
+
+	uploaded, err := svc.UploadImage(context.Background(), buf)
+	if err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+	stored, err := store.Get(context.Background(), strings.TrimPrefix(uploaded.Path, testPublicPath))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	return stored
+}
+
+func TestImageServiceUploadImageStripsMetadata(t *testing.T) {
+	tests := []struct {
+		name            string
+		buf             func(t *testing.T, orientation uint16) []byte
+		retainCopyright bool
+		wantCopyright   bool
+	}{
+		{name: "jpeg", buf: cameraJPEG},
+		{name: "png", buf: cameraPNG},
+		{name: "webp", buf: cameraWebP},
+		{name: "jpeg retaining copyright", buf: cameraJPEG, retainCopyright: true, wantCopyright: true},
+		{name: "png retaining copyright", buf: cameraPNG, retainCopyright: true, wantCopyright: true},
+		// webp output carries no metadata at all
+		{name: "webp retaining copyright", buf: cameraWebP, retainCopyright: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := tt.buf(t, 1)
+			if meta, err := imaging.ReadMetadata(src); err != nil || meta.Artist == "" {
+				t.Fatalf("source metadata = %+v, %v, want the camera tags", meta, err)
+			}
+
+			cfg := config.Config{PublicStoragePath: testPublicPath, Image: config.Image{RetainCopyright: tt.retainCopyright}}
+			stored := uploadAndRead(t, cfg, src)
+
+			if bytes.Contains(stored, []byte(secretMake)) {
+				t.Fatal("stored image still contains the camera make")
+			}
+			meta, err := imaging.ReadMetadata(stored)
+			if err != nil {
+				t.Fatalf("ReadMetadata() error = %v", err)
+			}
+			if tt.wantCopyright {
+				if meta.Artist != "Ana Lestari" || meta.Copyright != "(c) Universitas" {
+					t.Fatalf("stored metadata = %+v, want artist and copyright", *meta)
+				}
+			} else if meta.Artist != "" || meta.Copyright != "" {
+				t.Fatalf("stored metadata = %+v, want none", *meta)
+			}
+		})
+	}
+}
+
+func TestImageServiceUploadImageAppliesOrientation(t *testing.T) {
+	tests := []struct {
+		name        string
+		buf         func(t *testing.T, orientation uint16) []byte
+		orientation uint16
+		wantW       int
+		wantH       int
+	}{
+		{name: "jpeg upright", buf: cameraJPEG, orientation: 1, wantW: 400, wantH: 300},
+		{name: "jpeg rotated", buf: cameraJPEG, orientation: 6, wantW: 300, wantH: 400},
+		{name: "png rotated", buf: cameraPNG, orientation: 8, wantW: 300, wantH: 400},
+		{name: "webp rotated", buf: cameraWebP, orientation: 6, wantW: 300, wantH: 400},
+		{name: "png flipped", buf: cameraPNG, orientation: 3, wantW: 400, wantH: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := uploadAndRead(t, config.Config{PublicStoragePath: testPublicPath}, tt.buf(t, tt.orientation))
+
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(stored))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Fatalf("stored size = %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+			// the orientation is applied once, so the tag must not survive
+			if meta, _ := imaging.ReadMetadata(stored); meta.Orientation != 1 {
+				t.Fatalf("stored orientation = %d, want 1", meta.Orientation)
+			}
+		})
+	}
+}
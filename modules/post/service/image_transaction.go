@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"tracerstudy-post-service/modules/post/entity"
+)
+
+// ImageTransaction groups the file operations of one post mutation. New images are
+// staged under unique keys, so they never overwrite a file that is still referenced and
+// Rollback never removes a file another post points to. Commit removes the files the
+// mutation made obsolete, Rollback removes the staged ones. Call Commit only after the
+// database change succeeded, and pass both a context that outlives the request.
+type ImageTransaction struct {
+	svc *ImageService
+
+	mu       sync.Mutex
+	staged   []*UploadedImage
+	obsolete []*UploadedImage
+	done     bool
+}
+
+type ImageTransactionUseCase interface {
+	UploadImage(ctx context.Context, image []byte) (*UploadedImage, error)
+	DeleteImage(image string, variants entity.ImageVariants)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+func (svc *ImageService) Begin() ImageTransactionUseCase {
+	return &ImageTransaction{
+		svc: svc,
+	}
+}
+
+func (tx *ImageTransaction) UploadImage(ctx context.Context, image []byte) (*UploadedImage, error) {
+	uploaded, err := tx.svc.UploadImage(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.staged = append(tx.staged, uploaded)
+
+	return uploaded, nil
+}
+
+// DeleteImage schedules an image and its variants for removal on Commit.
+func (tx *ImageTransaction) DeleteImage(image string, variants entity.ImageVariants) {
+	if image == "" && len(variants) == 0 {
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.obsolete = append(tx.obsolete, &UploadedImage{Path: image, Variants: variants})
+}
+
+// Commit removes the obsolete files. The database change is already durable at this point,
+// so the caller only logs a failure; the garbage collector reclaims anything left behind.
+func (tx *ImageTransaction) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	return tx.remove(ctx, tx.obsolete)
+}
+
+// Rollback removes the staged files. It is a no-op after Commit, so it can be deferred.
+func (tx *ImageTransaction) Rollback(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	// deferred by the callers, so the error is logged here
+	err := tx.remove(ctx, tx.staged)
+	if err != nil {
+		slog.ErrorContext(ctx, "[ImageTransaction - Rollback] Error while remove staged images", "error", err)
+	}
+
+	return err
+}
+
+func (tx *ImageTransaction) remove(ctx context.Context, images []*UploadedImage) error {
+	var firstErr error
+	for _, image := range images {
+		if image.Path != "" {
+			if err := tx.svc.DeleteImage(ctx, image.Path); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if err := tx.svc.DeleteImageVariants(ctx, image.Variants); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+	"strings"
+	"testing"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/storage"
+)
+
+const testPublicPath = "/uploads/"
+
+func newTestImageService(t *testing.T) (*ImageService, *storage.MemoryStorage) {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	return NewImageService(config.Config{PublicStoragePath: testPublicPath}, store), store
+}
+
+// testPNG is wide enough to get a thumbnail variant.
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 300; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func storedKeys(t *testing.T, store storage.Storage) []string {
+	t.Helper()
+
+	objects, err := store.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func imageKeys(img *UploadedImage) []string {
+	keys := []string{strings.TrimPrefix(img.Path, testPublicPath)}
+	for _, v := range img.Variants {
+		keys = append(keys, strings.TrimPrefix(v.Path, testPublicPath), strings.TrimPrefix(v.WebpPath, testPublicPath))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func equalKeys(a, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+func TestImageTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		// run performs the mutation and returns the keys expected to remain
+		run func(t *testing.T, svc *ImageService, store storage.Storage) []string
+	}{
+		{
+			name: "rollback removes the staged image",
+			run: func(t *testing.T, svc *ImageService, store storage.Storage) []string {
+				ctx := context.Background()
+				tx := svc.Begin()
+				if _, err := tx.UploadImage(ctx, testPNG(t)); err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+				if err := tx.Rollback(ctx); err != nil {
+					t.Fatalf("Rollback() error = %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: "commit keeps the new image and removes the replaced one",
+			run: func(t *testing.T, svc *ImageService, store storage.Storage) []string {
+				ctx := context.Background()
+				old, err := svc.UploadImage(ctx, testPNG(t))
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+
+				tx := svc.Begin()
+				uploaded, err := tx.UploadImage(ctx, testPNG(t))
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+				tx.DeleteImage(old.Path, old.Variants)
+				if err := tx.Commit(ctx); err != nil {
+					t.Fatalf("Commit() error = %v", err)
+				}
+				// deferred by the handlers, a no-op after Commit
+				if err := tx.Rollback(ctx); err != nil {
+					t.Fatalf("Rollback() error = %v", err)
+				}
+				return imageKeys(uploaded)
+			},
+		},
+		{
+			name: "rollback keeps the image scheduled for removal",
+			run: func(t *testing.T, svc *ImageService, store storage.Storage) []string {
+				ctx := context.Background()
+				old, err := svc.UploadImage(ctx, testPNG(t))
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+
+				tx := svc.Begin()
+				if _, err := tx.UploadImage(ctx, testPNG(t)); err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+				tx.DeleteImage(old.Path, old.Variants)
+				if err := tx.Rollback(ctx); err != nil {
+					t.Fatalf("Rollback() error = %v", err)
+				}
+				return imageKeys(old)
+			},
+		},
+		{
+			name: "the same image uploaded twice is stored twice",
+			run: func(t *testing.T, svc *ImageService, store storage.Storage) []string {
+				ctx := context.Background()
+				first, err := svc.UploadImage(ctx, testPNG(t))
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+
+				tx := svc.Begin()
+				second, err := tx.UploadImage(ctx, testPNG(t))
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+				if second.Path == first.Path {
+					t.Fatalf("both uploads stored at %s", first.Path)
+				}
+				if err := tx.Rollback(ctx); err != nil {
+					t.Fatalf("Rollback() error = %v", err)
+				}
+				return imageKeys(first)
+			},
+		},
+		{
+			name: "commit leaves paths outside the public storage path alone",
+			run: func(t *testing.T, svc *ImageService, store storage.Storage) []string {
+				ctx := context.Background()
+				if err := store.Put(ctx, "cdn.jpg", []byte("cdn")); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+
+				tx := svc.Begin()
+				tx.DeleteImage("https://cdn.example.com/cdn.jpg", nil)
+				if err := tx.Commit(ctx); err != nil {
+					t.Fatalf("Commit() error = %v", err)
+				}
+				return []string{"cdn.jpg"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestImageService(t)
+
+			want := tt.run(t, svc, store)
+			sort.Strings(want)
+
+			if got := storedKeys(t, store); !equalKeys(got, want) {
+				t.Fatalf("stored keys = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestImageServiceUploadImage(t *testing.T) {
+	svc, store := newTestImageService(t)
+
+	uploaded, err := svc.UploadImage(context.Background(), testPNG(t))
+	if err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+
+	if !strings.HasPrefix(uploaded.Path, testPublicPath) || !strings.HasSuffix(uploaded.Path, ".png") {
+		t.Errorf("Path = %q, want a .png under %s", uploaded.Path, testPublicPath)
+	}
+	if _, ok := uploaded.Variants["thumbnail"]; !ok {
+		t.Errorf("Variants = %v, want a thumbnail", uploaded.Variants)
+	}
+	if uploaded.Blurhash == "" || uploaded.DominantColor == "" {
+		t.Errorf("placeholder = %q, %q, want both set", uploaded.Blurhash, uploaded.DominantColor)
+	}
+	if got, want := storedKeys(t, store), imageKeys(uploaded); !equalKeys(got, want) {
+		t.Errorf("stored keys = %v, want %v", got, want)
+	}
+}
+
+// pngHeader is a PNG that declares width x height pixels but holds no image data.
+func pngHeader(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 6 // 8 bit RGBA
+
+	chunk := append([]byte("IHDR"), ihdr...)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	return buf.Bytes()
+}
+
+func TestImageServiceUploadImageMaxPixels(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxPixels int64
+		image     func(t *testing.T) []byte
+		wantErr   bool
+	}{
+		{name: "within the limit", maxPixels: 400 * 300, image: testPNG},
+		{name: "above the limit", maxPixels: 400*300 - 1, image: testPNG, wantErr: true},
+		{
+			name:      "huge dimensions in a tiny file",
+			maxPixels: 40_000_000,
+			image:     func(t *testing.T) []byte { return pngHeader(100_000, 100_000) },
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStorage()
+			cfg := config.Config{PublicStoragePath: testPublicPath}
+			cfg.Image.MaxPixels = tt.maxPixels
+			svc := NewImageService(cfg, store)
+
+			_, err := svc.UploadImage(context.Background(), tt.image(t))
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("UploadImage() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errors.ErrValidation) {
+				t.Fatalf("UploadImage() error = %v, want a validation error", err)
+			}
+			if keys := storedKeys(t, store); len(keys) != 0 {
+				t.Fatalf("stored keys = %v, want none", keys)
+			}
+		})
+	}
+}
@@ -2,41 +2,61 @@ package service
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"strconv"
 	"time"
+	"tracerstudy-post-service/common/authorization"
 	"tracerstudy-post-service/common/config"
 	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/metrics"
 	"tracerstudy-post-service/common/utils"
+	auditEntity "tracerstudy-post-service/modules/audit/entity"
+	auditService "tracerstudy-post-service/modules/audit/service"
 	"tracerstudy-post-service/modules/post/entity"
 	"tracerstudy-post-service/modules/post/repository"
+	"tracerstudy-post-service/pb"
 )
 
+// ProfileProvider looks up the auth service profile of a caller.
+type ProfileProvider interface {
+	GetProfile(ctx context.Context, principal *authorization.Principal) (*pb.User, error)
+}
+
 type PostService struct {
 	cfg            config.Config
 	postRepository repository.PostRepositoryUseCase
+	auditSvc       auditService.AuditServiceUseCase
+	profiles       ProfileProvider
 }
 
-func NewPostService(cfg config.Config, postRepository repository.PostRepositoryUseCase) *PostService {
+// NewPostService stores the auth service username of the caller in created_by and
+// updated_by. The command line tools have no caller token and pass nil profiles, their
+// principal's credential is stored instead.
+func NewPostService(cfg config.Config, postRepository repository.PostRepositoryUseCase, auditSvc auditService.AuditServiceUseCase, profiles ProfileProvider) *PostService {
 	return &PostService{
 		cfg:            cfg,
 		postRepository: postRepository,
+		auditSvc:       auditSvc,
+		profiles:       profiles,
 	}
 }
 
 type PostServiceUseCase interface {
 	FindAll(ctx context.Context, req any) ([]*entity.Post, error)
 	FindById(ctx context.Context, id uint64) (*entity.Post, error)
-	Create(ctx context.Context, title, content, mainImagePath, mainImageCaption, tipe string, isFeatured uint32, createdBy, tags string) (*entity.Post, error)
+	Create(ctx context.Context, title, content string, image *UploadedImage, mainImageCaption, tipe string, isFeatured uint32, tags string) (*entity.Post, error)
 	Update(ctx context.Context, id uint64, fields *entity.Post) (*entity.Post, error)
 	Delete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, id uint64) (*entity.Post, error)
 	IncrementVisitor(ctx context.Context, id uint64) (*entity.Post, error)
+	CanManage(ctx context.Context, post *entity.Post) error
+	ImageReferences(ctx context.Context) ([]string, error)
 }
 
 func (svc *PostService) FindAll(ctx context.Context, req any) ([]*entity.Post, error) {
 	res, err := svc.postRepository.FindAll(ctx, req)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - FindAll] Error while find all post:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - FindAll] Error while find all post", "error", err)
 		return nil, err
 	}
 
@@ -46,35 +66,69 @@ func (svc *PostService) FindAll(ctx context.Context, req any) ([]*entity.Post, e
 func (svc *PostService) FindById(ctx context.Context, id uint64) (*entity.Post, error) {
 	res, err := svc.postRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - FindById] Error while find post by id:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - FindById] Error while find post by id", "error", err)
 		return nil, err
 	}
 
 	return res, nil
 }
 
-func (svc *PostService) Create(ctx context.Context, title, content, mainImagePath, mainImageCaption, tipe string, isFeatured uint32, createdBy, tags string) (*entity.Post, error) {
+func (svc *PostService) Create(ctx context.Context, title, content string, image *UploadedImage, mainImageCaption, tipe string, isFeatured uint32, tags string) (*entity.Post, error) {
+	principal, err := authorization.RequirePrincipal(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostService - Create] Error while get principal", "error", err)
+		return nil, err
+	}
+
+	// posts of Admin Prodi belong to their study program, the others are not scoped
+	var kodeProdi string
+	if principal.Role == authorization.RoleAdminProdi {
+		if principal.KodeProdi == "" {
+			slog.WarnContext(ctx, "[PostService - Create] Admin Prodi without study program", "cred", principal.Cred)
+			return nil, errors.Forbidden(errors.ReasonMissingStudyProgram, nil, "account has no study program to post for")
+		}
+		kodeProdi = principal.KodeProdi
+	}
+
+	username, err := svc.username(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &entity.Post{
 		Title:        title,
 		Slug:         utils.GenerateSlug(title),
 		Content:      content,
-		ImagePath:    mainImagePath,
+		ImagePath:    image.Path,
 		ImageCaption: mainImageCaption,
 		Type:         tipe,
 		IsFeatured:   isFeatured,
 		Visitors:     0,
-		CreatedBy:    createdBy,
-		UpdatedBy:    createdBy,
+		AuthorId:     principal.UserId,
+		KodeProdi:    kodeProdi,
+		CreatedBy:    username,
+		UpdatedBy:    username,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 		Tags:         tags,
+
+		ImageVariants:      image.Variants,
+		ImageBlurhash:      image.Blurhash,
+		ImageDominantColor: image.DominantColor,
 	}
 
-	res, err := svc.postRepository.Create(ctx, post)
+	var res *entity.Post
+	err = svc.postRepository.Transaction(ctx, func(ctx context.Context) error {
+		created, err := svc.postRepository.Create(ctx, post)
+		if err != nil {
+			slog.ErrorContext(ctx, "[PostService - Create] Error while create post", "error", err)
+			return err
+		}
+		res = created
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourcePost, res.Id, auditEntity.ActionCreate, nil, res)
+	})
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - Create] Error while create post:", parseError.Message)
 		return nil, err
 	}
 
@@ -82,10 +136,24 @@ func (svc *PostService) Create(ctx context.Context, title, content, mainImagePat
 }
 
 func (svc *PostService) Update(ctx context.Context, id uint64, fields *entity.Post) (*entity.Post, error) {
+	principal, err := authorization.RequirePrincipal(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostService - Update] Error while get principal", "error", err)
+		return nil, err
+	}
+
 	post, err := svc.postRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - Update] Error while find post by id:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - Update] Error while find post by id", "error", err)
+		return nil, err
+	}
+
+	if err := svc.CanManage(ctx, post); err != nil {
+		return nil, err
+	}
+
+	username, err := svc.username(ctx, principal)
+	if err != nil {
 		return nil, err
 	}
 
@@ -96,17 +164,35 @@ func (svc *PostService) Update(ctx context.Context, id uint64, fields *entity.Po
 		utils.AddItemToMap(updatedMap, "slug", utils.GenerateSlug(fields.Title))
 	}
 	utils.AddItemToMap(updatedMap, "content", fields.Content)
-	utils.AddItemToMap(updatedMap, "image_path", fields.ImagePath)
+	// a replaced image overwrites all of its columns, so an empty placeholder or variant
+	// list does not leave the previous image's values behind
+	if fields.ImagePath != "" {
+		updatedMap["image_path"] = fields.ImagePath
+		updatedMap["image_variants"] = fields.ImageVariants
+		updatedMap["image_blurhash"] = fields.ImageBlurhash
+		updatedMap["image_dominant_color"] = fields.ImageDominantColor
+	}
 	utils.AddItemToMap(updatedMap, "image_caption", fields.ImageCaption)
 	utils.AddItemToMap(updatedMap, "type", fields.Type)
 	utils.AddItemToMap(updatedMap, "is_featured", fields.IsFeatured)
-	utils.AddItemToMap(updatedMap, "updated_by", fields.UpdatedBy)
+	utils.AddItemToMap(updatedMap, "updated_by", username)
 	utils.AddItemToMap(updatedMap, "tags", fields.Tags)
 
-	res, err := svc.postRepository.Update(ctx, post, updatedMap)
+	// the repository writes the new values into post
+	before := *post
+
+	var res *entity.Post
+	err = svc.postRepository.Transaction(ctx, func(ctx context.Context) error {
+		updated, err := svc.postRepository.Update(ctx, post, updatedMap)
+		if err != nil {
+			slog.ErrorContext(ctx, "[PostService - Update] Error while update post", "error", err)
+			return err
+		}
+		res = updated
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourcePost, id, auditEntity.ActionUpdate, &before, res)
+	})
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - Update] Error while update post:", parseError.Message)
 		return nil, err
 	}
 
@@ -114,21 +200,63 @@ func (svc *PostService) Update(ctx context.Context, id uint64, fields *entity.Po
 }
 
 func (svc *PostService) Delete(ctx context.Context, id uint64) error {
-	err := svc.postRepository.Delete(ctx, id)
+	post, err := svc.postRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - Delete] Error while delete post:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - Delete] Error while find post by id", "error", err)
 		return err
 	}
 
-	return nil
+	if err := svc.CanManage(ctx, post); err != nil {
+		return err
+	}
+
+	return svc.postRepository.Transaction(ctx, func(ctx context.Context) error {
+		if err := svc.postRepository.Delete(ctx, id); err != nil {
+			slog.ErrorContext(ctx, "[PostService - Delete] Error while delete post", "error", err)
+			return err
+		}
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourcePost, id, auditEntity.ActionDelete, post, nil)
+	})
+}
+
+// Restore brings back a deleted post, the caller must be allowed to manage it.
+func (svc *PostService) Restore(ctx context.Context, id uint64) (*entity.Post, error) {
+	post, err := svc.postRepository.FindDeletedById(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostService - Restore] Error while find deleted post by id", "error", err)
+		return nil, err
+	}
+
+	if err := svc.CanManage(ctx, post); err != nil {
+		return nil, err
+	}
+
+	// the repository clears deleted_at of post
+	before := *post
+
+	var res *entity.Post
+	err = svc.postRepository.Transaction(ctx, func(ctx context.Context) error {
+		restored, err := svc.postRepository.Restore(ctx, post)
+		if err != nil {
+			slog.ErrorContext(ctx, "[PostService - Restore] Error while restore post", "error", err)
+			return err
+		}
+		res = restored
+
+		return svc.auditSvc.Record(ctx, auditEntity.ResourcePost, id, auditEntity.ActionRestore, &before, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
 
 func (svc *PostService) IncrementVisitor(ctx context.Context, id uint64) (*entity.Post, error) {
 	post, err := svc.postRepository.FindById(ctx, id)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - IncrementVisitor] Error while find post by id:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - IncrementVisitor] Error while find post by id", "error", err)
 		return nil, err
 	}
 
@@ -137,10 +265,85 @@ func (svc *PostService) IncrementVisitor(ctx context.Context, id uint64) (*entit
 
 	post, err = svc.postRepository.Update(ctx, post, updatedMap)
 	if err != nil {
-		parseError := errors.ParseError(err)
-		log.Println("ERROR: [PostService - IncrementVisitor] Error while increment visitor:", parseError.Message)
+		slog.ErrorContext(ctx, "[PostService - IncrementVisitor] Error while increment visitor", "error", err)
 		return nil, err
 	}
+	metrics.VisitorIncrements.Inc()
 
 	return post, nil
 }
+
+// CanManage tells whether the caller may edit or delete post. Super admins manage every
+// post, Admin Prodi the posts of their study program and everyone else their own posts.
+func (svc *PostService) CanManage(ctx context.Context, post *entity.Post) error {
+	principal, err := authorization.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case principal.Role == authorization.RoleSuperAdmin:
+		return nil
+	case principal.Role == authorization.RoleAdminProdi && principal.KodeProdi != "" && post.KodeProdi == principal.KodeProdi:
+		return nil
+	case post.AuthorId != 0 && post.AuthorId == principal.UserId:
+		return nil
+	case post.AuthorId == 0 && post.CreatedBy != "":
+		// posts created before author ids were stored only know the author's auth service
+		// username, which is not the token credential
+		username, err := svc.username(ctx, principal)
+		if err != nil {
+			return err
+		}
+		if post.CreatedBy == username {
+			return nil
+		}
+	}
+
+	slog.WarnContext(ctx, "[PostService - CanManage] Caller does not own the post", "id", post.Id, "cred", principal.Cred, "role", principal.Role)
+	return errors.Forbidden(errors.ReasonNotPostOwner, nil, "you are not allowed to manage this post").WithMetadata("id", strconv.FormatUint(post.Id, 10))
+}
+
+// username returns the auth service username of principal, falling back to its credential
+// when there is no profile provider or the profile has no username.
+func (svc *PostService) username(ctx context.Context, principal *authorization.Principal) (string, error) {
+	if svc.profiles == nil {
+		return principal.Cred, nil
+	}
+
+	profile, err := svc.profiles.GetProfile(ctx, principal)
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostService - username] Error while get profile", "cred", principal.Cred, "error", err)
+		return "", err
+	}
+	if profile.GetUsername() == "" {
+		return principal.Cred, nil
+	}
+
+	return profile.GetUsername(), nil
+}
+
+// ImageReferences lists the image and variant paths of every post, soft deleted ones included
+// until GC_DELETED_RETENTION has passed since their deletion.
+func (svc *PostService) ImageReferences(ctx context.Context) ([]string, error) {
+	var deletedSince time.Time
+	if retention := svc.cfg.GC.DeletedRetention; retention > 0 {
+		deletedSince = time.Now().Add(-retention)
+	}
+
+	posts, err := svc.postRepository.FindAllImages(ctx, deletedSince)
+	if err != nil {
+		slog.ErrorContext(ctx, "[PostService - ImageReferences] Error while find post images", "error", err)
+		return nil, err
+	}
+
+	var paths []string
+	for _, post := range posts {
+		paths = append(paths, post.ImagePath)
+		for _, v := range post.ImageVariants {
+			paths = append(paths, v.Path, v.WebpPath)
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	auditEntity "tracerstudy-post-service/modules/audit/entity"
+	"tracerstudy-post-service/modules/post/entity"
+	"tracerstudy-post-service/pb"
+)
+
+// fakePostRepository keeps posts in memory, deleted ones apart from the others.
+type fakePostRepository struct {
+	posts   map[uint64]*entity.Post
+	deleted map[uint64]*entity.Post
+}
+
+func newFakePostRepository() *fakePostRepository {
+	return &fakePostRepository{
+		posts:   make(map[uint64]*entity.Post),
+		deleted: make(map[uint64]*entity.Post),
+	}
+}
+
+func (r *fakePostRepository) FindAll(ctx context.Context, req any) ([]*entity.Post, error) {
+	return nil, nil
+}
+
+func (r *fakePostRepository) FindById(ctx context.Context, id uint64) (*entity.Post, error) {
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, errors.NotFound(errors.ReasonPostNotFound, nil, "post not found")
+	}
+
+	return post, nil
+}
+
+func (r *fakePostRepository) FindDeletedById(ctx context.Context, id uint64) (*entity.Post, error) {
+	post, ok := r.deleted[id]
+	if !ok {
+		return nil, errors.NotFound(errors.ReasonPostNotFound, nil, "deleted post not found")
+	}
+
+	return post, nil
+}
+
+func (r *fakePostRepository) Exists(ctx context.Context, id uint64) (bool, error) {
+	_, ok := r.posts[id]
+	return ok, nil
+}
+
+func (r *fakePostRepository) FindMissingPlaceholders(ctx context.Context) ([]*entity.Post, error) {
+	return nil, nil
+}
+
+func (r *fakePostRepository) UpdatePlaceholder(ctx context.Context, id uint64, blurhash, dominantColor string) error {
+	return nil
+}
+
+func (r *fakePostRepository) FindAllImages(ctx context.Context, deletedSince time.Time) ([]*entity.Post, error) {
+	return nil, nil
+}
+
+func (r *fakePostRepository) Create(ctx context.Context, req *entity.Post) (*entity.Post, error) {
+	req.Id = uint64(len(r.posts) + len(r.deleted) + 1)
+	r.posts[req.Id] = req
+	return req, nil
+}
+
+func (r *fakePostRepository) Update(ctx context.Context, post *entity.Post, updatedFields map[string]interface{}) (*entity.Post, error) {
+	return post, nil
+}
+
+func (r *fakePostRepository) Delete(ctx context.Context, id uint64) error {
+	r.deleted[id] = r.posts[id]
+	delete(r.posts, id)
+	return nil
+}
+
+func (r *fakePostRepository) Restore(ctx context.Context, post *entity.Post) (*entity.Post, error) {
+	delete(r.deleted, post.Id)
+	r.posts[post.Id] = post
+	return post, nil
+}
+
+// Transaction puts the posts back as they were when fn fails.
+func (r *fakePostRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	posts, deleted := maps.Clone(r.posts), maps.Clone(r.deleted)
+	if err := fn(ctx); err != nil {
+		r.posts, r.deleted = posts, deleted
+		return err
+	}
+
+	return nil
+}
+
+// fakeAuditService records the audited actions, or fails with err while it is set.
+type fakeAuditService struct {
+	actions []string
+	err     error
+}
+
+func (a *fakeAuditService) FindAll(ctx context.Context, filter *auditEntity.AuditFilter) ([]*auditEntity.AuditEvent, error) {
+	return nil, nil
+}
+
+func (a *fakeAuditService) Record(ctx context.Context, resource string, resourceId uint64, action string, before, after any) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+// fakeProfiles returns the auth service username stored for a credential.
+type fakeProfiles map[string]string
+
+func (p fakeProfiles) GetProfile(ctx context.Context, principal *authorization.Principal) (*pb.User, error) {
+	username, ok := p[principal.Cred]
+	if !ok {
+		return nil, errors.Unavailable(errors.ReasonAuthServiceUnavailable, nil, "auth service is unavailable")
+	}
+
+	return &pb.User{Username: username}, nil
+}
+
+func TestPostServiceCanManage(t *testing.T) {
+	profiles := fakeProfiles{
+		"admin@example.com": "admin",
+		"prodi@example.com": "prodi",
+		"post@example.com":  "adminpost",
+	}
+
+	tests := []struct {
+		name      string
+		principal *authorization.Principal
+		post      *entity.Post
+		profiles  ProfileProvider
+		wantErr   error
+	}{
+		{
+			name:      "super admin manages every post",
+			principal: &authorization.Principal{UserId: 1, Cred: "root@example.com", Role: authorization.RoleSuperAdmin},
+			post:      &entity.Post{Id: 1, AuthorId: 9},
+		},
+		{
+			name:      "author",
+			principal: &authorization.Principal{UserId: 9, Cred: "post@example.com", Role: authorization.RoleAdminPost},
+			post:      &entity.Post{Id: 1, AuthorId: 9},
+		},
+		{
+			name:      "another author",
+			principal: &authorization.Principal{UserId: 8, Cred: "post@example.com", Role: authorization.RoleAdminPost},
+			post:      &entity.Post{Id: 1, AuthorId: 9, CreatedBy: "adminpost"},
+			wantErr:   errors.ErrForbidden,
+		},
+		{
+			name:      "admin prodi of the post's study program",
+			principal: &authorization.Principal{UserId: 3, Cred: "prodi@example.com", Role: authorization.RoleAdminProdi, KodeProdi: "55201"},
+			post:      &entity.Post{Id: 1, AuthorId: 9, KodeProdi: "55201"},
+		},
+		{
+			name:      "admin prodi of another study program",
+			principal: &authorization.Principal{UserId: 3, Cred: "prodi@example.com", Role: authorization.RoleAdminProdi, KodeProdi: "55202"},
+			post:      &entity.Post{Id: 1, AuthorId: 9, KodeProdi: "55201"},
+			wantErr:   errors.ErrForbidden,
+		},
+		{
+			name:      "admin prodi without study program",
+			principal: &authorization.Principal{UserId: 3, Cred: "prodi@example.com", Role: authorization.RoleAdminProdi},
+			post:      &entity.Post{Id: 1, AuthorId: 9},
+			wantErr:   errors.ErrForbidden,
+		},
+		{
+			name:      "legacy post created by the caller's username",
+			principal: &authorization.Principal{UserId: 2, Cred: "admin@example.com", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1, CreatedBy: "admin"},
+			profiles:  profiles,
+		},
+		{
+			name:      "legacy post matched against the credential is denied",
+			principal: &authorization.Principal{UserId: 2, Cred: "admin@example.com", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1, CreatedBy: "admin@example.com"},
+			profiles:  profiles,
+			wantErr:   errors.ErrForbidden,
+		},
+		{
+			name:      "legacy post of another username",
+			principal: &authorization.Principal{UserId: 2, Cred: "admin@example.com", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1, CreatedBy: "adminpost"},
+			profiles:  profiles,
+			wantErr:   errors.ErrForbidden,
+		},
+		{
+			name:      "legacy post while the auth service is down",
+			principal: &authorization.Principal{UserId: 2, Cred: "unknown@example.com", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1, CreatedBy: "admin"},
+			profiles:  profiles,
+			wantErr:   errors.ErrDependencyUnavailable,
+		},
+		{
+			name:      "legacy post without profile provider uses the credential",
+			principal: &authorization.Principal{UserId: 2, Cred: "admin", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1, CreatedBy: "admin"},
+		},
+		{
+			name:      "legacy post without creator",
+			principal: &authorization.Principal{UserId: 2, Cred: "admin@example.com", Role: authorization.RoleAdmin},
+			post:      &entity.Post{Id: 1},
+			profiles:  profiles,
+			wantErr:   errors.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPostService(config.Config{}, newFakePostRepository(), &fakeAuditService{}, tt.profiles)
+			ctx := authorization.WithPrincipal(context.Background(), tt.principal)
+
+			err := svc.CanManage(ctx, tt.post)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("CanManage() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CanManage() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostServiceCanManageWithoutPrincipal(t *testing.T) {
+	svc := NewPostService(config.Config{}, newFakePostRepository(), &fakeAuditService{}, nil)
+
+	if err := svc.CanManage(context.Background(), &entity.Post{Id: 1}); !errors.Is(err, errors.ErrUnauthenticated) {
+		t.Fatalf("CanManage() error = %v, want %v", err, errors.ErrUnauthenticated)
+	}
+}
+
+func TestPostServiceCreateStoresOwner(t *testing.T) {
+	repo := newFakePostRepository()
+	audit := &fakeAuditService{}
+	svc := NewPostService(config.Config{}, repo, audit, fakeProfiles{"prodi@example.com": "prodi"})
+	principal := &authorization.Principal{UserId: 3, Cred: "prodi@example.com", Role: authorization.RoleAdminProdi, KodeProdi: "55201"}
+	ctx := authorization.WithPrincipal(context.Background(), principal)
+
+	post, err := svc.Create(ctx, "Reuni", "isi", &UploadedImage{}, "", "berita", 0, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if post.AuthorId != 3 || post.KodeProdi != "55201" {
+		t.Errorf("owner = %d, %q, want 3, %q", post.AuthorId, post.KodeProdi, "55201")
+	}
+	if post.CreatedBy != "prodi" || post.UpdatedBy != "prodi" {
+		t.Errorf("CreatedBy, UpdatedBy = %q, %q, want the auth service username", post.CreatedBy, post.UpdatedBy)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != auditEntity.ActionCreate {
+		t.Errorf("audited actions = %v, want [%s]", audit.actions, auditEntity.ActionCreate)
+	}
+
+	// the author manages the post even after their username changes
+	if err := svc.CanManage(ctx, post); err != nil {
+		t.Fatalf("CanManage() error = %v", err)
+	}
+}
+
+func TestPostServiceRestore(t *testing.T) {
+	owner := &authorization.Principal{UserId: 9, Cred: "post@example.com", Role: authorization.RoleAdminPost}
+	other := &authorization.Principal{UserId: 8, Cred: "other@example.com", Role: authorization.RoleAdminPost}
+
+	tests := []struct {
+		name        string
+		principal   *authorization.Principal
+		deleted     bool
+		wantErr     error
+		wantActions []string
+	}{
+		{
+			name:        "owner restores a deleted post",
+			principal:   owner,
+			deleted:     true,
+			wantActions: []string{auditEntity.ActionDelete, auditEntity.ActionRestore},
+		},
+		{
+			name:        "another author",
+			principal:   other,
+			deleted:     true,
+			wantErr:     errors.ErrForbidden,
+			wantActions: []string{auditEntity.ActionDelete},
+		},
+		{
+			name:      "post that was not deleted",
+			principal: owner,
+			wantErr:   errors.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakePostRepository()
+			audit := &fakeAuditService{}
+			svc := NewPostService(config.Config{}, repo, audit, nil)
+			repo.posts[1] = &entity.Post{Id: 1, AuthorId: owner.UserId}
+
+			if tt.deleted {
+				if err := svc.Delete(authorization.WithPrincipal(context.Background(), owner), 1); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			}
+
+			_, err := svc.Restore(authorization.WithPrincipal(context.Background(), tt.principal), 1)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore() error = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Join(audit.actions, ",") != strings.Join(tt.wantActions, ",") {
+				t.Fatalf("audited actions = %v, want %v", audit.actions, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestPostServiceRollsBackWithoutAudit(t *testing.T) {
+	owner := &authorization.Principal{UserId: 9, Cred: "post@example.com", Role: authorization.RoleAdminPost}
+	ctx := authorization.WithPrincipal(context.Background(), owner)
+	auditErr := errors.Unavailable(errors.ReasonDatabaseUnavailable, nil, "database is unavailable")
+
+	tests := []struct {
+		name   string
+		mutate func(svc *PostService) error
+	}{
+		{
+			name: "create",
+			mutate: func(svc *PostService) error {
+				_, err := svc.Create(ctx, "Reuni", "isi", &UploadedImage{}, "", "berita", 0, "")
+				return err
+			},
+		},
+		{
+			name:   "delete",
+			mutate: func(svc *PostService) error { return svc.Delete(ctx, 1) },
+		},
+		{
+			name: "restore",
+			mutate: func(svc *PostService) error {
+				_, err := svc.Restore(ctx, 2)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakePostRepository()
+			repo.posts[1] = &entity.Post{Id: 1, AuthorId: owner.UserId}
+			repo.deleted[2] = &entity.Post{Id: 2, AuthorId: owner.UserId}
+			svc := NewPostService(config.Config{}, repo, &fakeAuditService{err: auditErr}, fakeProfiles{owner.Cred: "adminpost"})
+
+			if err := tt.mutate(svc); !errors.Is(err, errors.ErrDependencyUnavailable) {
+				t.Fatalf("error = %v, want the audit error", err)
+			}
+			// post 1 is live and post 2 deleted, as before the mutation
+			if len(repo.posts) != 1 || repo.posts[1] == nil || len(repo.deleted) != 1 || repo.deleted[2] == nil {
+				t.Fatalf("posts, deleted = %v, %v, want the mutation rolled back", repo.posts, repo.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package builder
+
+import (
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/modules/revocation/handler"
+	"tracerstudy-post-service/modules/revocation/repository"
+	"tracerstudy-post-service/modules/revocation/service"
+
+	"gorm.io/gorm"
+)
+
+// BuildRevocationService is shared by the handler and AuthInterceptor, so changes made
+// through the RPCs reach the interceptor's cache right away.
+func BuildRevocationService(cfg config.Config, db *gorm.DB) *service.RevocationService {
+	revocationRepo := repository.NewRevocationRepository(db)
+
+	return service.NewRevocationService(cfg, revocationRepo)
+}
+
+func BuildRevocationHandler(cfg config.Config, revocationSvc service.RevocationServiceUseCase) *handler.RevocationHandler {
+	return handler.NewRevocationHandler(cfg, revocationSvc)
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+	"tracerstudy-post-service/pb"
+)
+
+const (
+	RevocationTableName = "token_revocations"
+)
+
+// Revocation rejects either the token with Jti, or every token of the user (UserId or
+// Cred) issued before IssuedBefore. It can be dropped once ExpiresAt has passed, as the
+// tokens it covers have expired by then.
+type Revocation struct {
+	Id           uint64    `json:"id"`
+	Jti          string    `gorm:"index" json:"jti"`
+	UserId       uint64    `gorm:"index" json:"user_id"`
+	Cred         string    `gorm:"index" json:"cred"`
+	IssuedBefore time.Time `json:"issued_before"`
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+	Reason       string    `json:"reason"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (r *Revocation) TableName() string {
+	return RevocationTableName
+}
+
+func ConvertEntityToProto(r *Revocation) *pb.Revocation {
+	res := &pb.Revocation{
+		Id:        r.Id,
+		Jti:       r.Jti,
+		UserId:    r.UserId,
+		Cred:      r.Cred,
+		ExpiresAt: r.ExpiresAt.Format(time.RFC3339),
+		Reason:    r.Reason,
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+	if !r.IssuedBefore.IsZero() {
+		res.IssuedBefore = r.IssuedBefore.Format(time.RFC3339)
+	}
+
+	return res
+}
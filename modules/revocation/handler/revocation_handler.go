@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/utils"
+	"tracerstudy-post-service/modules/revocation/entity"
+	"tracerstudy-post-service/modules/revocation/service"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type RevocationHandler struct {
+	pb.UnimplementedRevocationServiceServer
+	config        config.Config
+	revocationSvc service.RevocationServiceUseCase
+}
+
+func NewRevocationHandler(config config.Config, revocationService service.RevocationServiceUseCase) *RevocationHandler {
+	return &RevocationHandler{
+		config:        config,
+		revocationSvc: revocationService,
+	}
+}
+
+func (rh *RevocationHandler) RevokeToken(ctx context.Context, req *pb.RevokeTokenRequest) (*pb.GetRevocationResponse, error) {
+	expiresAt, err := utils.ParseTimestamp("expires_at", req.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	revocation, err := rh.revocationSvc.RevokeToken(ctx, req.GetJti(), expiresAt, req.GetReason())
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationHandler - RevokeToken] Error while revoke token", "error", err)
+		return nil, err
+	}
+
+	return &pb.GetRevocationResponse{
+		Code:    uint32(http.StatusCreated),
+		Message: "revoke token success",
+		Data:    entity.ConvertEntityToProto(revocation),
+	}, nil
+}
+
+func (rh *RevocationHandler) RevokeUser(ctx context.Context, req *pb.RevokeUserRequest) (*pb.GetRevocationResponse, error) {
+	issuedBefore, err := utils.ParseTimestamp("issued_before", req.GetIssuedBefore())
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := utils.ParseTimestamp("expires_at", req.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	revocation, err := rh.revocationSvc.RevokeUser(ctx, req.GetUserId(), req.GetCred(), issuedBefore, expiresAt, req.GetReason())
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationHandler - RevokeUser] Error while revoke user", "error", err)
+		return nil, err
+	}
+
+	return &pb.GetRevocationResponse{
+		Code:    uint32(http.StatusCreated),
+		Message: "revoke user success",
+		Data:    entity.ConvertEntityToProto(revocation),
+	}, nil
+}
+
+func (rh *RevocationHandler) GetAllRevocations(ctx context.Context, req *emptypb.Empty) (*pb.GetAllRevocationsResponse, error) {
+	revocations, err := rh.revocationSvc.FindAll(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationHandler - GetAllRevocations] Error while get all revocations", "error", err)
+		return nil, err
+	}
+
+	var revocationArr []*pb.Revocation
+	for _, r := range revocations {
+		revocationArr = append(revocationArr, entity.ConvertEntityToProto(r))
+	}
+
+	return &pb.GetAllRevocationsResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "get all revocations success",
+		Data:    revocationArr,
+	}, nil
+}
+
+func (rh *RevocationHandler) DeleteRevocation(ctx context.Context, req *pb.GetRevocationByIdRequest) (*pb.DeleteRevocationResponse, error) {
+	err := rh.revocationSvc.Delete(ctx, req.GetId())
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationHandler - DeleteRevocation] Error while delete revocation", "error", err)
+		return nil, err
+	}
+
+	return &pb.DeleteRevocationResponse{
+		Code:    uint32(http.StatusOK),
+		Message: "delete revocation success",
+	}, nil
+}
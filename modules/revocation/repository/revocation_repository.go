@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+	commonErrors "tracerstudy-post-service/common/errors"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/common/tracing"
+	"tracerstudy-post-service/modules/revocation/entity"
+
+	"gorm.io/gorm"
+)
+
+type RevocationRepository struct {
+	db *gorm.DB
+}
+
+func NewRevocationRepository(db *gorm.DB) *RevocationRepository {
+	return &RevocationRepository{
+		db: db,
+	}
+}
+
+type RevocationRepositoryUseCase interface {
+	FindActive(ctx context.Context, now time.Time) ([]*entity.Revocation, error)
+	Create(ctx context.Context, req *entity.Revocation) (*entity.Revocation, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+func (r *RevocationRepository) FindActive(ctx context.Context, now time.Time) ([]*entity.Revocation, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "RevocationRepository - FindActive")
+	defer span.End()
+
+	var revocations []*entity.Revocation
+	if err := r.db.WithContext(ctxSpan).Where("expires_at > ?", now).Order("created_at desc").Find(&revocations).Error; err != nil {
+		slog.ErrorContext(ctx, "[RevocationRepository - FindActive] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return revocations, nil
+}
+
+func (r *RevocationRepository) Create(ctx context.Context, req *entity.Revocation) (*entity.Revocation, error) {
+	ctxSpan, span := tracing.StartSpan(ctx, "RevocationRepository - Create")
+	defer span.End()
+
+	if err := r.db.WithContext(ctxSpan).Create(req).Error; err != nil {
+		slog.ErrorContext(ctx, "[RevocationRepository - Create] Internal server error", "error", err)
+		return nil, gormConn.DatabaseError(err)
+	}
+
+	return req, nil
+}
+
+func (r *RevocationRepository) Delete(ctx context.Context, id uint64) error {
+	ctxSpan, span := tracing.StartSpan(ctx, "RevocationRepository - Delete")
+	defer span.End()
+
+	res := r.db.WithContext(ctxSpan).Where("id = ?", id).Delete(&entity.Revocation{})
+	if res.Error != nil {
+		slog.ErrorContext(ctx, "[RevocationRepository - Delete] Internal server error", "error", res.Error)
+		return gormConn.DatabaseError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		slog.WarnContext(ctx, "[RevocationRepository - Delete] Record not found", "id", id)
+		return commonErrors.NotFound(commonErrors.ReasonRevocationNotFound, nil, "revocation %d not found", id).WithMetadata("id", strconv.FormatUint(id, 10))
+	}
+
+	return nil
+}
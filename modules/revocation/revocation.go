@@ -0,0 +1,23 @@
+package revocation
+
+import (
+	"tracerstudy-post-service/common/config"
+	gormConn "tracerstudy-post-service/common/gorm"
+	"tracerstudy-post-service/modules/revocation/builder"
+	"tracerstudy-post-service/modules/revocation/entity"
+	"tracerstudy-post-service/modules/revocation/service"
+	"tracerstudy-post-service/pb"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+func InitGrpc(server *grpc.Server, cfg config.Config, revocationSvc service.RevocationServiceUseCase) {
+	revocation := builder.BuildRevocationHandler(cfg, revocationSvc)
+	pb.RegisterRevocationServiceServer(server, revocation)
+}
+
+// Migrate creates the token_revocations table, every authenticated RPC reads it.
+func Migrate(db *gorm.DB) error {
+	return gormConn.Migrate(db, &entity.Revocation{})
+}
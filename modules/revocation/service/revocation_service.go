@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	commonJwt "tracerstudy-post-service/common/jwt"
+	"tracerstudy-post-service/modules/revocation/entity"
+	"tracerstudy-post-service/modules/revocation/repository"
+)
+
+// RevocationService keeps the active revocations in memory so AuthInterceptor can check
+// every token without a query. The list is reloaded once it is older than the cache TTL,
+// and right away after a change made through this replica.
+type RevocationService struct {
+	cfg                  config.Config
+	revocationRepository repository.RevocationRepositoryUseCase
+
+	mu       sync.RWMutex
+	active   *revocationSet
+	loadedAt time.Time
+	loadMu   sync.Mutex
+}
+
+type revocationSet struct {
+	jtis  map[string]struct{}
+	users map[uint64]time.Time
+	creds map[string]time.Time
+}
+
+func NewRevocationService(cfg config.Config, revocationRepository repository.RevocationRepositoryUseCase) *RevocationService {
+	return &RevocationService{
+		cfg:                  cfg,
+		revocationRepository: revocationRepository,
+	}
+}
+
+type RevocationServiceUseCase interface {
+	FindAll(ctx context.Context) ([]*entity.Revocation, error)
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time, reason string) (*entity.Revocation, error)
+	RevokeUser(ctx context.Context, userId uint64, cred string, issuedBefore, expiresAt time.Time, reason string) (*entity.Revocation, error)
+	Delete(ctx context.Context, id uint64) error
+	IsRevoked(ctx context.Context, claims *commonJwt.CustomClaims) (bool, error)
+}
+
+func (svc *RevocationService) FindAll(ctx context.Context) ([]*entity.Revocation, error) {
+	res, err := svc.revocationRepository.FindActive(ctx, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationService - FindAll] Error while find active revocations", "error", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RevokeToken rejects the token with jti. A zero expiresAt keeps the revocation for as
+// long as a token issued now would live.
+func (svc *RevocationService) RevokeToken(ctx context.Context, jti string, expiresAt time.Time, reason string) (*entity.Revocation, error) {
+	now := time.Now()
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(svc.tokenLifetime())
+	}
+
+	return svc.create(ctx, &entity.Revocation{
+		Jti:       jti,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+		CreatedAt: now,
+	})
+}
+
+// RevokeUser rejects the tokens of the user issued before issuedBefore, now when it is zero.
+func (svc *RevocationService) RevokeUser(ctx context.Context, userId uint64, cred string, issuedBefore, expiresAt time.Time, reason string) (*entity.Revocation, error) {
+	if userId == 0 && cred == "" {
+		return nil, errors.Validation(errors.ReasonMissingUser, nil, "either user_id or cred is required")
+	}
+
+	now := time.Now()
+	if issuedBefore.IsZero() {
+		issuedBefore = now
+	}
+	if expiresAt.IsZero() {
+		expiresAt = issuedBefore.Add(svc.tokenLifetime())
+	}
+
+	return svc.create(ctx, &entity.Revocation{
+		UserId:       userId,
+		Cred:         cred,
+		IssuedBefore: issuedBefore,
+		ExpiresAt:    expiresAt,
+		Reason:       reason,
+		CreatedAt:    now,
+	})
+}
+
+func (svc *RevocationService) create(ctx context.Context, revocation *entity.Revocation) (*entity.Revocation, error) {
+	if principal, ok := authorization.PrincipalFromContext(ctx); ok {
+		revocation.CreatedBy = principal.Cred
+	}
+
+	res, err := svc.revocationRepository.Create(ctx, revocation)
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationService - create] Error while create revocation", "error", err)
+		return nil, err
+	}
+
+	svc.invalidate()
+	slog.InfoContext(ctx, "[RevocationService - create] Revocation created",
+		"id", res.Id, "jti", res.Jti, "user_id", res.UserId, "cred", res.Cred, "created_by", res.CreatedBy)
+
+	return res, nil
+}
+
+func (svc *RevocationService) Delete(ctx context.Context, id uint64) error {
+	err := svc.revocationRepository.Delete(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "[RevocationService - Delete] Error while delete revocation", "error", err)
+		return err
+	}
+
+	svc.invalidate()
+
+	return nil
+}
+
+// IsRevoked reports whether claims belong to a revoked token. Tokens without iat are
+// treated as issued at the beginning of time.
+func (svc *RevocationService) IsRevoked(ctx context.Context, claims *commonJwt.CustomClaims) (bool, error) {
+	active, err := svc.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := active.jtis[claims.ID]; ok && claims.ID != "" {
+		return true, nil
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	if before, ok := active.users[claims.UserId]; ok && claims.UserId != 0 && issuedAt.Before(before) {
+		return true, nil
+	}
+	if before, ok := active.creds[claims.Cred]; ok && claims.Cred != "" && issuedAt.Before(before) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// load returns the cached list, reloading it when it is stale. A failed reload keeps
+// serving the previous list, so only a cold cache fails closed.
+func (svc *RevocationService) load(ctx context.Context) (*revocationSet, error) {
+	if active, ok := svc.cached(); ok {
+		return active, nil
+	}
+
+	svc.loadMu.Lock()
+	defer svc.loadMu.Unlock()
+
+	if active, ok := svc.cached(); ok {
+		return active, nil
+	}
+
+	now := time.Now()
+	revocations, err := svc.revocationRepository.FindActive(ctx, now)
+	if err != nil {
+		svc.mu.RLock()
+		stale := svc.active
+		svc.mu.RUnlock()
+		if stale != nil {
+			slog.ErrorContext(ctx, "[RevocationService - load] Error while reload revocations, serving the previous list", "error", err)
+			return stale, nil
+		}
+		slog.ErrorContext(ctx, "[RevocationService - load] Error while load revocations", "error", err)
+		return nil, err
+	}
+
+	active := &revocationSet{
+		jtis:  make(map[string]struct{}),
+		users: make(map[uint64]time.Time),
+		creds: make(map[string]time.Time),
+	}
+	for _, r := range revocations {
+		if r.Jti != "" {
+			active.jtis[r.Jti] = struct{}{}
+			continue
+		}
+		if r.UserId != 0 && r.IssuedBefore.After(active.users[r.UserId]) {
+			active.users[r.UserId] = r.IssuedBefore
+		}
+		if r.Cred != "" && r.IssuedBefore.After(active.creds[r.Cred]) {
+			active.creds[r.Cred] = r.IssuedBefore
+		}
+	}
+
+	svc.mu.Lock()
+	svc.active = active
+	svc.loadedAt = now
+	svc.mu.Unlock()
+
+	return active, nil
+}
+
+func (svc *RevocationService) cached() (*revocationSet, bool) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	if svc.active == nil || time.Since(svc.loadedAt) >= svc.cfg.Revocation.CacheTTL {
+		return nil, false
+	}
+
+	return svc.active, true
+}
+
+func (svc *RevocationService) invalidate() {
+	svc.mu.Lock()
+	svc.loadedAt = time.Time{}
+	svc.mu.Unlock()
+}
+
+func (svc *RevocationService) tokenLifetime() time.Duration {
+	return svc.cfg.JWT.TokenDuration + svc.cfg.JWT.ClockSkew
+}
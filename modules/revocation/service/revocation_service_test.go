@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/config"
+	commonJwt "tracerstudy-post-service/common/jwt"
+	"tracerstudy-post-service/modules/revocation/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeRevocationRepository returns revocations from FindActive, or err while it is set.
+type fakeRevocationRepository struct {
+	revocations []*entity.Revocation
+	err         error
+	finds       int
+}
+
+func (r *fakeRevocationRepository) FindActive(ctx context.Context, now time.Time) ([]*entity.Revocation, error) {
+	r.finds++
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return r.revocations, nil
+}
+
+func (r *fakeRevocationRepository) Create(ctx context.Context, req *entity.Revocation) (*entity.Revocation, error) {
+	req.Id = uint64(len(r.revocations) + 1)
+	r.revocations = append(r.revocations, req)
+	return req, nil
+}
+
+func (r *fakeRevocationRepository) Delete(ctx context.Context, id uint64) error {
+	for i, rev := range r.revocations {
+		if rev.Id == id {
+			r.revocations = append(r.revocations[:i], r.revocations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newTestRevocationService(repo *fakeRevocationRepository) *RevocationService {
+	cfg := config.Config{
+		Revocation: config.Revocation{CacheTTL: time.Hour},
+		JWT:        config.JWTConfig{TokenDuration: time.Hour},
+	}
+
+	return NewRevocationService(cfg, repo)
+}
+
+func testClaims(jti string, userId uint64, cred string, issuedAt time.Time) *commonJwt.CustomClaims {
+	claims := &commonJwt.CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: jti},
+		UserId:           userId,
+		Cred:             cred,
+	}
+	if !issuedAt.IsZero() {
+		claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+	}
+
+	return claims
+}
+
+func TestRevocationServiceIsRevoked(t *testing.T) {
+	boundary := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	revocations := []*entity.Revocation{
+		{Id: 1, Jti: "stolen"},
+		{Id: 2, UserId: 7, IssuedBefore: boundary},
+		// an older revocation of the same user does not move the boundary back
+		{Id: 3, UserId: 7, IssuedBefore: boundary.Add(-time.Hour)},
+		{Id: 4, Cred: "alumni@example.com", IssuedBefore: boundary},
+		// a jti revocation does not revoke the other tokens of its user
+		{Id: 5, Jti: "single", UserId: 9, Cred: "other@example.com", IssuedBefore: boundary},
+	}
+
+	tests := []struct {
+		name   string
+		claims *commonJwt.CustomClaims
+		want   bool
+	}{
+		{name: "revoked jti", claims: testClaims("stolen", 1, "admin@example.com", boundary.Add(time.Hour)), want: true},
+		{name: "other jti", claims: testClaims("fresh", 1, "admin@example.com", boundary.Add(-time.Hour))},
+		{name: "user issued before", claims: testClaims("", 7, "", boundary.Add(-time.Second)), want: true},
+		{name: "user issued at the boundary", claims: testClaims("", 7, "", boundary)},
+		{name: "user issued after", claims: testClaims("", 7, "", boundary.Add(time.Second))},
+		{name: "cred issued before", claims: testClaims("", 0, "alumni@example.com", boundary.Add(-time.Second)), want: true},
+		{name: "cred issued at the boundary", claims: testClaims("", 0, "alumni@example.com", boundary)},
+		{name: "cred issued after", claims: testClaims("", 0, "alumni@example.com", boundary.Add(time.Second))},
+		{name: "user without iat", claims: testClaims("", 7, "", time.Time{}), want: true},
+		{name: "cred without iat", claims: testClaims("", 0, "alumni@example.com", time.Time{}), want: true},
+		{name: "other user without iat", claims: testClaims("", 8, "admin@example.com", time.Time{})},
+		{name: "user of a jti revocation", claims: testClaims("", 9, "other@example.com", boundary.Add(-time.Hour))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestRevocationService(&fakeRevocationRepository{revocations: revocations})
+
+			got, err := svc.IsRevoked(context.Background(), tt.claims)
+			if err != nil {
+				t.Fatalf("IsRevoked() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevocationServiceCache(t *testing.T) {
+	ctx := context.Background()
+	claims := testClaims("stolen", 7, "alumni@example.com", time.Now())
+
+	isRevoked := func(t *testing.T, svc *RevocationService) bool {
+		t.Helper()
+
+		got, err := svc.IsRevoked(ctx, claims)
+		if err != nil {
+			t.Fatalf("IsRevoked() error = %v", err)
+		}
+		return got
+	}
+
+	t.Run("cached list is served until invalidated", func(t *testing.T) {
+		repo := &fakeRevocationRepository{}
+		svc := newTestRevocationService(repo)
+
+		if isRevoked(t, svc) {
+			t.Fatal("IsRevoked() = true before any revocation")
+		}
+		// written by another replica, this one keeps its cache until the TTL passes
+		repo.revocations = append(repo.revocations, &entity.Revocation{Id: 1, Jti: "stolen"})
+		if isRevoked(t, svc) || repo.finds != 1 {
+			t.Fatalf("IsRevoked() reloaded a fresh cache, %d finds", repo.finds)
+		}
+
+		if _, err := svc.RevokeUser(ctx, 0, "other@example.com", time.Time{}, time.Time{}, "test"); err != nil {
+			t.Fatalf("RevokeUser() error = %v", err)
+		}
+		if !isRevoked(t, svc) || repo.finds != 2 {
+			t.Fatalf("IsRevoked() did not reload after invalidate, %d finds", repo.finds)
+		}
+
+		if err := svc.Delete(ctx, 1); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if isRevoked(t, svc) {
+			t.Fatal("IsRevoked() = true after the revocation was deleted")
+		}
+	})
+
+	t.Run("stale list is served when a reload fails", func(t *testing.T) {
+		repo := &fakeRevocationRepository{revocations: []*entity.Revocation{{Id: 1, Jti: "stolen"}}}
+		svc := newTestRevocationService(repo)
+
+		if !isRevoked(t, svc) {
+			t.Fatal("IsRevoked() = false for a revoked jti")
+		}
+
+		repo.err = errors.New("database is unavailable")
+		svc.invalidate()
+		if !isRevoked(t, svc) || repo.finds != 2 {
+			t.Fatalf("IsRevoked() did not serve the previous list, %d finds", repo.finds)
+		}
+	})
+
+	t.Run("cold cache fails closed", func(t *testing.T) {
+		repo := &fakeRevocationRepository{err: errors.New("database is unavailable")}
+		svc := newTestRevocationService(repo)
+
+		if _, err := svc.IsRevoked(ctx, claims); err == nil {
+			t.Fatal("IsRevoked() error = nil, want the load error")
+		}
+
+		// the failure is not cached, the next call tries again
+		repo.err = nil
+		if isRevoked(t, svc) || repo.finds != 2 {
+			t.Fatalf("IsRevoked() after recovery, %d finds", repo.finds)
+		}
+	})
+}
@@ -26,6 +26,7 @@ const (
 	CommentService_CreateComment_FullMethodName       = "/tracer_study_grpc.CommentService/CreateComment"
 	CommentService_ReplyComment_FullMethodName        = "/tracer_study_grpc.CommentService/ReplyComment"
 	CommentService_DeleteComment_FullMethodName       = "/tracer_study_grpc.CommentService/DeleteComment"
+	CommentService_RestoreComment_FullMethodName      = "/tracer_study_grpc.CommentService/RestoreComment"
 )
 
 // CommentServiceClient is the client API for CommentService service.
@@ -38,6 +39,8 @@ type CommentServiceClient interface {
 	CreateComment(ctx context.Context, in *Comment, opts ...grpc.CallOption) (*GetCommentResponse, error)
 	ReplyComment(ctx context.Context, in *Comment, opts ...grpc.CallOption) (*GetCommentResponse, error)
 	DeleteComment(ctx context.Context, in *GetCommentByIdRequest, opts ...grpc.CallOption) (*DeleteCommentResponse, error)
+	// RestoreComment brings back a deleted comment.
+	RestoreComment(ctx context.Context, in *GetCommentByIdRequest, opts ...grpc.CallOption) (*GetCommentResponse, error)
 }
 
 type commentServiceClient struct {
@@ -102,6 +105,15 @@ func (c *commentServiceClient) DeleteComment(ctx context.Context, in *GetComment
 	return out, nil
 }
 
+func (c *commentServiceClient) RestoreComment(ctx context.Context, in *GetCommentByIdRequest, opts ...grpc.CallOption) (*GetCommentResponse, error) {
+	out := new(GetCommentResponse)
+	err := c.cc.Invoke(ctx, CommentService_RestoreComment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CommentServiceServer is the server API for CommentService service.
 // All implementations must embed UnimplementedCommentServiceServer
 // for forward compatibility
@@ -112,6 +124,8 @@ type CommentServiceServer interface {
 	CreateComment(context.Context, *Comment) (*GetCommentResponse, error)
 	ReplyComment(context.Context, *Comment) (*GetCommentResponse, error)
 	DeleteComment(context.Context, *GetCommentByIdRequest) (*DeleteCommentResponse, error)
+	// RestoreComment brings back a deleted comment.
+	RestoreComment(context.Context, *GetCommentByIdRequest) (*GetCommentResponse, error)
 	mustEmbedUnimplementedCommentServiceServer()
 }
 
@@ -137,6 +151,9 @@ func (UnimplementedCommentServiceServer) ReplyComment(context.Context, *Comment)
 func (UnimplementedCommentServiceServer) DeleteComment(context.Context, *GetCommentByIdRequest) (*DeleteCommentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteComment not implemented")
 }
+func (UnimplementedCommentServiceServer) RestoreComment(context.Context, *GetCommentByIdRequest) (*GetCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreComment not implemented")
+}
 func (UnimplementedCommentServiceServer) mustEmbedUnimplementedCommentServiceServer() {}
 
 // UnsafeCommentServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -258,6 +275,24 @@ func _CommentService_DeleteComment_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommentService_RestoreComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommentByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentServiceServer).RestoreComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommentService_RestoreComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentServiceServer).RestoreComment(ctx, req.(*GetCommentByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CommentService_ServiceDesc is the grpc.ServiceDesc for CommentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -289,6 +324,10 @@ var CommentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteComment",
 			Handler:    _CommentService_DeleteComment_Handler,
 		},
+		{
+			MethodName: "RestoreComment",
+			Handler:    _CommentService_RestoreComment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "comment.proto",
@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: media.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MediaService_UploadMedia_FullMethodName       = "/tracer_study_grpc.MediaService/UploadMedia"
+	MediaService_GetMediaById_FullMethodName      = "/tracer_study_grpc.MediaService/GetMediaById"
+	MediaService_DeleteMedia_FullMethodName       = "/tracer_study_grpc.MediaService/DeleteMedia"
+	MediaService_GetSignedMediaUrl_FullMethodName = "/tracer_study_grpc.MediaService/GetSignedMediaUrl"
+)
+
+// MediaServiceClient is the client API for MediaService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MediaServiceClient interface {
+	UploadMedia(ctx context.Context, in *UploadMediaRequest, opts ...grpc.CallOption) (*GetMediaResponse, error)
+	GetMediaById(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*GetMediaResponse, error)
+	DeleteMedia(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*DeleteMediaResponse, error)
+	GetSignedMediaUrl(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*GetSignedMediaUrlResponse, error)
+}
+
+type mediaServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMediaServiceClient(cc grpc.ClientConnInterface) MediaServiceClient {
+	return &mediaServiceClient{cc}
+}
+
+func (c *mediaServiceClient) UploadMedia(ctx context.Context, in *UploadMediaRequest, opts ...grpc.CallOption) (*GetMediaResponse, error) {
+	out := new(GetMediaResponse)
+	err := c.cc.Invoke(ctx, MediaService_UploadMedia_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mediaServiceClient) GetMediaById(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*GetMediaResponse, error) {
+	out := new(GetMediaResponse)
+	err := c.cc.Invoke(ctx, MediaService_GetMediaById_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mediaServiceClient) DeleteMedia(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*DeleteMediaResponse, error) {
+	out := new(DeleteMediaResponse)
+	err := c.cc.Invoke(ctx, MediaService_DeleteMedia_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mediaServiceClient) GetSignedMediaUrl(ctx context.Context, in *GetMediaByIdRequest, opts ...grpc.CallOption) (*GetSignedMediaUrlResponse, error) {
+	out := new(GetSignedMediaUrlResponse)
+	err := c.cc.Invoke(ctx, MediaService_GetSignedMediaUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MediaServiceServer is the server API for MediaService service.
+// All implementations must embed UnimplementedMediaServiceServer
+// for forward compatibility
+type MediaServiceServer interface {
+	UploadMedia(context.Context, *UploadMediaRequest) (*GetMediaResponse, error)
+	GetMediaById(context.Context, *GetMediaByIdRequest) (*GetMediaResponse, error)
+	DeleteMedia(context.Context, *GetMediaByIdRequest) (*DeleteMediaResponse, error)
+	GetSignedMediaUrl(context.Context, *GetMediaByIdRequest) (*GetSignedMediaUrlResponse, error)
+	mustEmbedUnimplementedMediaServiceServer()
+}
+
+// UnimplementedMediaServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMediaServiceServer struct {
+}
+
+func (UnimplementedMediaServiceServer) UploadMedia(context.Context, *UploadMediaRequest) (*GetMediaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadMedia not implemented")
+}
+func (UnimplementedMediaServiceServer) GetMediaById(context.Context, *GetMediaByIdRequest) (*GetMediaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMediaById not implemented")
+}
+func (UnimplementedMediaServiceServer) DeleteMedia(context.Context, *GetMediaByIdRequest) (*DeleteMediaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMedia not implemented")
+}
+func (UnimplementedMediaServiceServer) GetSignedMediaUrl(context.Context, *GetMediaByIdRequest) (*GetSignedMediaUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSignedMediaUrl not implemented")
+}
+func (UnimplementedMediaServiceServer) mustEmbedUnimplementedMediaServiceServer() {}
+
+// UnsafeMediaServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MediaServiceServer will
+// result in compilation errors.
+type UnsafeMediaServiceServer interface {
+	mustEmbedUnimplementedMediaServiceServer()
+}
+
+func RegisterMediaServiceServer(s grpc.ServiceRegistrar, srv MediaServiceServer) {
+	s.RegisterService(&MediaService_ServiceDesc, srv)
+}
+
+func _MediaService_UploadMedia_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadMediaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MediaServiceServer).UploadMedia(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MediaService_UploadMedia_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MediaServiceServer).UploadMedia(ctx, req.(*UploadMediaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MediaService_GetMediaById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMediaByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MediaServiceServer).GetMediaById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MediaService_GetMediaById_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MediaServiceServer).GetMediaById(ctx, req.(*GetMediaByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MediaService_DeleteMedia_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMediaByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MediaServiceServer).DeleteMedia(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MediaService_DeleteMedia_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MediaServiceServer).DeleteMedia(ctx, req.(*GetMediaByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MediaService_GetSignedMediaUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMediaByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MediaServiceServer).GetSignedMediaUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MediaService_GetSignedMediaUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MediaServiceServer).GetSignedMediaUrl(ctx, req.(*GetMediaByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MediaService_ServiceDesc is the grpc.ServiceDesc for MediaService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MediaService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tracer_study_grpc.MediaService",
+	HandlerType: (*MediaServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UploadMedia",
+			Handler:    _MediaService_UploadMedia_Handler,
+		},
+		{
+			MethodName: "GetMediaById",
+			Handler:    _MediaService_GetMediaById_Handler,
+		},
+		{
+			MethodName: "DeleteMedia",
+			Handler:    _MediaService_DeleteMedia_Handler,
+		},
+		{
+			MethodName: "GetSignedMediaUrl",
+			Handler:    _MediaService_GetSignedMediaUrl_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "media.proto",
+}
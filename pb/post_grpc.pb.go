@@ -26,6 +26,7 @@ const (
 	PostService_UpdatePost_FullMethodName  = "/tracer_study_grpc.PostService/UpdatePost"
 	PostService_DeletePost_FullMethodName  = "/tracer_study_grpc.PostService/DeletePost"
 	PostService_AddVisitor_FullMethodName  = "/tracer_study_grpc.PostService/AddVisitor"
+	PostService_RestorePost_FullMethodName = "/tracer_study_grpc.PostService/RestorePost"
 )
 
 // PostServiceClient is the client API for PostService service.
@@ -38,6 +39,8 @@ type PostServiceClient interface {
 	UpdatePost(ctx context.Context, in *CreatePostRequest, opts ...grpc.CallOption) (*GetPostResponse, error)
 	DeletePost(ctx context.Context, in *GetPostByIdRequest, opts ...grpc.CallOption) (*DeletePostResponse, error)
 	AddVisitor(ctx context.Context, in *GetPostByIdRequest, opts ...grpc.CallOption) (*GetPostResponse, error)
+	// RestorePost brings back a deleted post.
+	RestorePost(ctx context.Context, in *GetPostByIdRequest, opts ...grpc.CallOption) (*GetPostResponse, error)
 }
 
 type postServiceClient struct {
@@ -102,6 +105,15 @@ func (c *postServiceClient) AddVisitor(ctx context.Context, in *GetPostByIdReque
 	return out, nil
 }
 
+func (c *postServiceClient) RestorePost(ctx context.Context, in *GetPostByIdRequest, opts ...grpc.CallOption) (*GetPostResponse, error) {
+	out := new(GetPostResponse)
+	err := c.cc.Invoke(ctx, PostService_RestorePost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostServiceServer is the server API for PostService service.
 // All implementations must embed UnimplementedPostServiceServer
 // for forward compatibility
@@ -112,6 +124,8 @@ type PostServiceServer interface {
 	UpdatePost(context.Context, *CreatePostRequest) (*GetPostResponse, error)
 	DeletePost(context.Context, *GetPostByIdRequest) (*DeletePostResponse, error)
 	AddVisitor(context.Context, *GetPostByIdRequest) (*GetPostResponse, error)
+	// RestorePost brings back a deleted post.
+	RestorePost(context.Context, *GetPostByIdRequest) (*GetPostResponse, error)
 	mustEmbedUnimplementedPostServiceServer()
 }
 
@@ -137,6 +151,9 @@ func (UnimplementedPostServiceServer) DeletePost(context.Context, *GetPostByIdRe
 func (UnimplementedPostServiceServer) AddVisitor(context.Context, *GetPostByIdRequest) (*GetPostResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddVisitor not implemented")
 }
+func (UnimplementedPostServiceServer) RestorePost(context.Context, *GetPostByIdRequest) (*GetPostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestorePost not implemented")
+}
 func (UnimplementedPostServiceServer) mustEmbedUnimplementedPostServiceServer() {}
 
 // UnsafePostServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -258,6 +275,24 @@ func _PostService_AddVisitor_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_RestorePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).RestorePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_RestorePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).RestorePost(ctx, req.(*GetPostByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PostService_ServiceDesc is the grpc.ServiceDesc for PostService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -289,6 +324,10 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddVisitor",
 			Handler:    _PostService_AddVisitor_Handler,
 		},
+		{
+			MethodName: "RestorePost",
+			Handler:    _PostService_RestorePost_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "post.proto",
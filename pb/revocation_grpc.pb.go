@@ -0,0 +1,221 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: revocation.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RevocationService_RevokeToken_FullMethodName       = "/tracer_study_grpc.RevocationService/RevokeToken"
+	RevocationService_RevokeUser_FullMethodName        = "/tracer_study_grpc.RevocationService/RevokeUser"
+	RevocationService_GetAllRevocations_FullMethodName = "/tracer_study_grpc.RevocationService/GetAllRevocations"
+	RevocationService_DeleteRevocation_FullMethodName  = "/tracer_study_grpc.RevocationService/DeleteRevocation"
+)
+
+// RevocationServiceClient is the client API for RevocationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RevocationServiceClient interface {
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*GetRevocationResponse, error)
+	RevokeUser(ctx context.Context, in *RevokeUserRequest, opts ...grpc.CallOption) (*GetRevocationResponse, error)
+	GetAllRevocations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetAllRevocationsResponse, error)
+	DeleteRevocation(ctx context.Context, in *GetRevocationByIdRequest, opts ...grpc.CallOption) (*DeleteRevocationResponse, error)
+}
+
+type revocationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRevocationServiceClient(cc grpc.ClientConnInterface) RevocationServiceClient {
+	return &revocationServiceClient{cc}
+}
+
+func (c *revocationServiceClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*GetRevocationResponse, error) {
+	out := new(GetRevocationResponse)
+	err := c.cc.Invoke(ctx, RevocationService_RevokeToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *revocationServiceClient) RevokeUser(ctx context.Context, in *RevokeUserRequest, opts ...grpc.CallOption) (*GetRevocationResponse, error) {
+	out := new(GetRevocationResponse)
+	err := c.cc.Invoke(ctx, RevocationService_RevokeUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *revocationServiceClient) GetAllRevocations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetAllRevocationsResponse, error) {
+	out := new(GetAllRevocationsResponse)
+	err := c.cc.Invoke(ctx, RevocationService_GetAllRevocations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *revocationServiceClient) DeleteRevocation(ctx context.Context, in *GetRevocationByIdRequest, opts ...grpc.CallOption) (*DeleteRevocationResponse, error) {
+	out := new(DeleteRevocationResponse)
+	err := c.cc.Invoke(ctx, RevocationService_DeleteRevocation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RevocationServiceServer is the server API for RevocationService service.
+// All implementations must embed UnimplementedRevocationServiceServer
+// for forward compatibility
+type RevocationServiceServer interface {
+	RevokeToken(context.Context, *RevokeTokenRequest) (*GetRevocationResponse, error)
+	RevokeUser(context.Context, *RevokeUserRequest) (*GetRevocationResponse, error)
+	GetAllRevocations(context.Context, *emptypb.Empty) (*GetAllRevocationsResponse, error)
+	DeleteRevocation(context.Context, *GetRevocationByIdRequest) (*DeleteRevocationResponse, error)
+	mustEmbedUnimplementedRevocationServiceServer()
+}
+
+// UnimplementedRevocationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRevocationServiceServer struct {
+}
+
+func (UnimplementedRevocationServiceServer) RevokeToken(context.Context, *RevokeTokenRequest) (*GetRevocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedRevocationServiceServer) RevokeUser(context.Context, *RevokeUserRequest) (*GetRevocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeUser not implemented")
+}
+func (UnimplementedRevocationServiceServer) GetAllRevocations(context.Context, *emptypb.Empty) (*GetAllRevocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllRevocations not implemented")
+}
+func (UnimplementedRevocationServiceServer) DeleteRevocation(context.Context, *GetRevocationByIdRequest) (*DeleteRevocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRevocation not implemented")
+}
+func (UnimplementedRevocationServiceServer) mustEmbedUnimplementedRevocationServiceServer() {}
+
+// UnsafeRevocationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RevocationServiceServer will
+// result in compilation errors.
+type UnsafeRevocationServiceServer interface {
+	mustEmbedUnimplementedRevocationServiceServer()
+}
+
+func RegisterRevocationServiceServer(s grpc.ServiceRegistrar, srv RevocationServiceServer) {
+	s.RegisterService(&RevocationService_ServiceDesc, srv)
+}
+
+func _RevocationService_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServiceServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RevocationService_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServiceServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RevocationService_RevokeUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServiceServer).RevokeUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RevocationService_RevokeUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServiceServer).RevokeUser(ctx, req.(*RevokeUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RevocationService_GetAllRevocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServiceServer).GetAllRevocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RevocationService_GetAllRevocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServiceServer).GetAllRevocations(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RevocationService_DeleteRevocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRevocationByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServiceServer).DeleteRevocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RevocationService_DeleteRevocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServiceServer).DeleteRevocation(ctx, req.(*GetRevocationByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RevocationService_ServiceDesc is the grpc.ServiceDesc for RevocationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RevocationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tracer_study_grpc.RevocationService",
+	HandlerType: (*RevocationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RevokeToken",
+			Handler:    _RevocationService_RevokeToken_Handler,
+		},
+		{
+			MethodName: "RevokeUser",
+			Handler:    _RevocationService_RevokeUser_Handler,
+		},
+		{
+			MethodName: "GetAllRevocations",
+			Handler:    _RevocationService_GetAllRevocations_Handler,
+		},
+		{
+			MethodName: "DeleteRevocation",
+			Handler:    _RevocationService_DeleteRevocation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "revocation.proto",
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+)
+
+// FileServer serves stored files below PublicStoragePath with caching headers and
+// range request support. Keys under privatePrefix are only served when the request
+// URL carries a valid, unexpired signature, and are never cached by shared caches.
+type FileServer struct {
+	cfg           config.Config
+	storage       storage.Storage
+	signer        *signer.Signer
+	privatePrefix string
+}
+
+func NewFileServer(cfg config.Config, store storage.Storage, urlSigner *signer.Signer, privatePrefix string) *FileServer {
+	return &FileServer{
+		cfg:           cfg,
+		storage:       store,
+		signer:        urlSigner,
+		privatePrefix: privatePrefix,
+	}
+}
+
+func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, fs.cfg.PublicStoragePath) {
+		http.NotFound(w, r)
+		return
+	}
+	// clean the key so "media/../private/x" can not bypass the private prefix check
+	key := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(r.URL.Path, fs.cfg.PublicStoragePath)), "/")
+
+	private := fs.privatePrefix != "" && strings.HasPrefix(key, fs.privatePrefix)
+	if private {
+		if err := fs.signer.Verify(r.URL.Path, r.URL.Query()); err != nil {
+			slog.WarnContext(r.Context(), "[FileServer - ServeHTTP] Rejected private file request", "error", err)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	obj, err := fs.storage.Stat(r.Context(), key)
+	if err != nil {
+		fs.writeError(w, r, err)
+		return
+	}
+
+	data, err := fs.storage.Get(r.Context(), key)
+	if err != nil {
+		fs.writeError(w, r, err)
+		return
+	}
+
+	if private {
+		w.Header().Set("Cache-Control", "private, no-store")
+	} else {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(fs.cfg.HTTP.CacheMaxAge.Seconds())))
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, obj.ModTime.UnixNano(), obj.Size))
+	}
+
+	http.ServeContent(w, r, key, obj.ModTime, bytes.NewReader(data))
+}
+
+func (fs *FileServer) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		http.NotFound(w, r)
+		return
+	}
+
+	slog.ErrorContext(r.Context(), "[FileServer - ServeHTTP] Error while read file", "error", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
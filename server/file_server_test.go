@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/signer"
+	"tracerstudy-post-service/common/storage"
+)
+
+const (
+	testPublicPath    = "/uploads/"
+	testPrivatePrefix = "private/"
+)
+
+func newTestFileServer(t *testing.T) (*FileServer, *signer.Signer) {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	for key, data := range map[string]string{
+		"post.png":           "public image",
+		"private/report.pdf": "private report",
+		"private/other.pdf":  "other report",
+	} {
+		if err := store.Put(context.Background(), key, []byte(data)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	urlSigner := signer.NewSigner("media-secret")
+	cfg := config.Config{PublicStoragePath: testPublicPath, HTTP: config.HTTP{CacheMaxAge: time.Hour}}
+
+	return NewFileServer(cfg, store, urlSigner, testPrivatePrefix), urlSigner
+}
+
+func TestFileServer(t *testing.T) {
+	fs, urlSigner := newTestFileServer(t)
+
+	valid := urlSigner.Sign("/uploads/private/report.pdf", time.Now().Add(time.Minute))
+	signedURL, err := url.Parse(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// validQuery is reused on other paths, which the signature does not cover
+	validQuery := "?" + signedURL.RawQuery
+
+	tests := []struct {
+		name         string
+		target       string
+		wantStatus   int
+		wantBody     string
+		wantCacheCtl string
+	}{
+		{
+			name:         "public file",
+			target:       "/uploads/post.png",
+			wantStatus:   http.StatusOK,
+			wantBody:     "public image",
+			wantCacheCtl: "public, max-age=3600",
+		},
+		{
+			name:         "valid signature",
+			target:       valid,
+			wantStatus:   http.StatusOK,
+			wantBody:     "private report",
+			wantCacheCtl: "private, no-store",
+		},
+		{
+			name:       "missing signature",
+			target:     "/uploads/private/report.pdf",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "expired",
+			target:     urlSigner.Sign("/uploads/private/report.pdf", time.Now().Add(-time.Second)),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "tampered path",
+			target:     "/uploads/private/other.pdf" + validQuery,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "tampered expiry",
+			target: "/uploads/private/report.pdf?" + url.Values{
+				signer.QueryExpires:   {"4102444800"},
+				signer.QuerySignature: {signedURL.Query().Get(signer.QuerySignature)},
+			}.Encode(),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "traversal into the private prefix",
+			target:     "/uploads/public/../private/report.pdf",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "encoded traversal into the private prefix",
+			target:     "/uploads/public/..%2fprivate/report.pdf",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "traversal reusing a valid signature",
+			target:     "/uploads/private/report.pdf/../other.pdf" + validQuery,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "traversal out of the storage",
+			target:     "/uploads/../../etc/passwd",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing file",
+			target:     "/uploads/missing.png",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "outside the public path",
+			target:     "/other/post.png",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			fs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Fatalf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCacheCtl {
+				t.Fatalf("Cache-Control = %q, want %q", got, tt.wantCacheCtl)
+			}
+		})
+	}
+}
+
+func TestFileServerMethodNotAllowed(t *testing.T) {
+	fs, _ := newTestFileServer(t)
+
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/uploads/post.png", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Fatalf("status = %d, Allow = %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
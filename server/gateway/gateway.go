@@ -0,0 +1,257 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/logger"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	maxBodySize       = 1024 * 1024 * 150
+	maxMultipartInMem = 1024 * 1024 * 32
+)
+
+var (
+	marshaler   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// HandlerFunc transcodes one HTTP request into a gRPC call. ctx already carries the
+// outgoing metadata of the request, so the call passes through the server interceptors.
+type HandlerFunc func(ctx context.Context, r *http.Request) (proto.Message, error)
+
+// Handle registers fn for a Go 1.22 mux pattern such as "GET /v1/posts/{id}".
+func Handle(mux *http.ServeMux, pattern string, fn HandlerFunc) {
+	mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		requestID := logger.NewRequestID(r.Header.Get(logger.RequestIDHeader))
+		r.Header.Set(logger.RequestIDHeader, requestID)
+		w.Header().Set(logger.RequestIDHeader, requestID)
+
+		res, err := fn(OutgoingContext(r), r)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		WriteProto(w, res)
+	}))
+}
+
+// OutgoingContext forwards the authorization and request id headers and the client address as gRPC metadata.
+func OutgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		md.Set("authorization", auth)
+	}
+	if requestID := r.Header.Get(logger.RequestIDHeader); requestID != "" {
+		md.Set(logger.RequestIDHeader, requestID)
+	}
+
+	clientIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		clientIP = host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		clientIP = forwarded + ", " + clientIP
+	}
+	md.Set("x-forwarded-for", clientIP)
+
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// DecodeJSON unmarshals a JSON request body into msg. An empty body leaves msg untouched.
+func DecodeJSON(r *http.Request, msg proto.Message) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return invalidRequest(err, "request body could not be read")
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	if err := unmarshaler.Unmarshal(body, msg); err != nil {
+		return invalidRequest(err, "request body is not valid JSON for this endpoint")
+	}
+
+	return nil
+}
+
+// invalidRequest keeps the parser's error in the cause, the client gets message and the reason.
+func invalidRequest(cause error, format string, args ...any) error {
+	slog.Warn("[Gateway - invalidRequest] Invalid request", "error", cause)
+	return errors.ToStatus(errors.Validation(errors.ReasonInvalidRequest, cause, format, args...))
+}
+
+// IsMultipart reports whether the request body is multipart/form-data.
+func IsMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// ParseMultipart parses a multipart/form-data body and returns the named file, if any.
+func ParseMultipart(r *http.Request, fileField string) (string, []byte, error) {
+	if err := r.ParseMultipartForm(maxMultipartInMem); err != nil {
+		return "", nil, invalidRequest(err, "request body is not valid multipart form data")
+	}
+
+	file, header, err := r.FormFile(fileField)
+	if err == http.ErrMissingFile {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, invalidRequest(err, "file %s could not be read", fileField)
+	}
+	defer file.Close()
+
+	buf, err := io.ReadAll(file)
+	if err != nil {
+		return "", nil, invalidRequest(err, "file %s could not be read", fileField)
+	}
+
+	return header.Filename, buf, nil
+}
+
+// FormUint32 reads an optional numeric form value.
+func FormUint32(r *http.Request, key string) (uint32, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, invalidRequest(err, "%s must be a number", key)
+	}
+
+	return uint32(n), nil
+}
+
+// PathUint64 reads a numeric path wildcard such as {id}.
+func PathUint64(r *http.Request, name string) (uint64, error) {
+	n, err := strconv.ParseUint(r.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, invalidRequest(err, "%s must be a number", name)
+	}
+
+	return n, nil
+}
+
+// WriteProto writes msg as JSON. The HTTP status follows the "code" field of the
+// response message when it holds a valid HTTP status, and is 200 otherwise.
+func WriteProto(w http.ResponseWriter, msg proto.Message) {
+	body, err := marshaler.Marshal(msg)
+	if err != nil {
+		slog.Error("[Gateway - WriteProto] Error while marshal response", "error", err)
+		WriteError(w, errors.ToStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(responseCode(msg))
+	_, _ = w.Write(body)
+}
+
+func responseCode(msg proto.Message) int {
+	field := msg.ProtoReflect().Descriptor().Fields().ByName("code")
+	if field == nil || field.Kind() != protoreflect.Uint32Kind {
+		return http.StatusOK
+	}
+
+	code := int(msg.ProtoReflect().Get(field).Uint())
+	if code < 100 || code > 599 {
+		return http.StatusOK
+	}
+
+	return code
+}
+
+type errorResponse struct {
+	Code       int              `json:"code"`
+	Message    string           `json:"message"`
+	Reason     string           `json:"reason,omitempty"`
+	Violations []fieldViolation `json:"violations,omitempty"`
+}
+
+type fieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// WriteError maps a gRPC status to its HTTP status and writes it as JSON.
+func WriteError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := HTTPStatusFromCode(st.Code())
+	if code >= http.StatusInternalServerError {
+		slog.Error("[Gateway - WriteError] RPC failed", "error", err)
+	}
+
+	res := &errorResponse{
+		Code:    code,
+		Message: st.Message(),
+	}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			res.Reason = d.GetReason()
+		case *errdetails.RetryInfo:
+			w.Header().Set("Retry-After", strconv.FormatInt(errors.RetryAfterSeconds(d.GetRetryDelay().AsDuration()), 10))
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				res.Violations = append(res.Violations, fieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// HTTPStatusFromCode follows the mapping in google/rpc/code.proto.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,28 +1,32 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	roles "tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
 	commonJwt "tracerstudy-post-service/common/jwt"
+	"tracerstudy-post-service/common/ratelimit"
+	"tracerstudy-post-service/common/validation"
 	"tracerstudy-post-service/server/interceptor"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	connProtocol  = "tcp"
-	maxMsgSize    = 1024 * 1024 * 150
-	tokenDuration = 5 * time.Minute
-	secretKey     = "secret"
+	connProtocol = "tcp"
+	maxMsgSize   = 1024 * 1024 * 150
+
+	defaultShutdownTimeout = 15 * time.Second
 )
 
 type Grpc struct {
@@ -44,19 +48,61 @@ func NewGrpc(port string, options ...grpc.ServerOption) *Grpc {
 }
 
 func NewGrpcServer(
-	port string,
+	cfg config.Config,
 	jwtManager *commonJwt.JWT,
+	policy *authorization.PolicyStore,
+	revocations interceptor.RevocationChecker,
+	limiter ratelimit.Store,
+	validator *validation.Validator,
+	tlsConfig *tls.Config,
 ) *Grpc {
 	// var options grpc.ServerOption
 	// options := grpc_middleware.WithUnaryServerChain()
 	// add option unary interceptor
-	// jwtManager := commonJwt.NewJWT(secretKey, tokenDuration)
 	// authInterceptor := interceptor.NewAuthInterceptor(jwtManager, accessibleRoles())
-	authInterceptor := interceptor.NewAuthInterceptor(jwtManager, roles.GetAccessibleRoles())
+	authInterceptor := interceptor.NewAuthInterceptor(jwtManager, policy, revocations)
+	metricsInterceptor := interceptor.NewMetricsInterceptor()
+	tracingInterceptor := interceptor.NewTracingInterceptor()
+	requestIDInterceptor := interceptor.NewRequestIDInterceptor()
+	loggingInterceptor := interceptor.NewLoggingInterceptor()
+	recoveryInterceptor := interceptor.NewRecoveryInterceptor()
+	errorInterceptor := interceptor.NewErrorInterceptor()
+	deadlineInterceptor := interceptor.NewDeadlineInterceptor(cfg.Grpc.DefaultTimeout, cfg.Grpc.MethodTimeouts)
+	rateLimitInterceptor := interceptor.NewRateLimitInterceptor(cfg, limiter)
+	validationInterceptor := interceptor.NewValidationInterceptor(validator)
+
+	// recovery sits inside logging and metrics so a recovered panic is reported as Internal,
+	// errors sits right below so everything logged and counted is already a status
 	options := []grpc.ServerOption{
-		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		grpc.ChainUnaryInterceptor(
+			requestIDInterceptor.Unary(),
+			tracingInterceptor.Unary(),
+			metricsInterceptor.Unary(),
+			loggingInterceptor.Unary(),
+			recoveryInterceptor.Unary(),
+			errorInterceptor.Unary(),
+			deadlineInterceptor.Unary(),
+			authInterceptor.Unary(),
+			rateLimitInterceptor.Unary(),
+			validationInterceptor.Unary(),
+		),
+		grpc.ChainStreamInterceptor(
+			requestIDInterceptor.Stream(),
+			tracingInterceptor.Stream(),
+			metricsInterceptor.Stream(),
+			loggingInterceptor.Stream(),
+			recoveryInterceptor.Stream(),
+			errorInterceptor.Stream(),
+			deadlineInterceptor.Stream(),
+			authInterceptor.Stream(),
+			rateLimitInterceptor.Stream(),
+			validationInterceptor.Stream(),
+		),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
-	server := NewGrpc(port, options...)
+	server := NewGrpc(cfg.Port.GRPC, options...)
 	return server
 }
 
@@ -68,7 +114,7 @@ func (g *Grpc) Run() error {
 	}
 
 	go g.serve()
-	log.Printf("grpc server is running on port %s\n", g.Port)
+	slog.Info("grpc server is running", "port", g.Port)
 	return nil
 }
 
@@ -79,10 +125,26 @@ func (g *Grpc) serve() {
 }
 
 func (g *Grpc) AwaitTermination() error {
-	sign := make(chan os.Signal, 1)
-	signal.Notify(sign, syscall.SIGINT, syscall.SIGTERM)
-	<-sign
+	return AwaitTermination(defaultShutdownTimeout, g)
+}
+
+// Stop drains in-flight RPCs and forces the remaining ones closed when ctx is done.
+func (g *Grpc) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		g.Server.GracefulStop()
+		close(stopped)
+	}()
 
-	g.Server.GracefulStop()
-	return g.listener.Close()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.Server.Stop()
+	}
+
+	if err := g.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return nil
 }
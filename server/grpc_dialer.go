@@ -1,27 +1,38 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-type DialOption func(name string) (grpc.DialOption, error)
+// dialSettings collects the DialOptions of a connection. The connection is plaintext
+// unless tls is set.
+type dialSettings struct {
+	tls     *tls.Config
+	options []grpc.DialOption
+}
 
-func Dial(name string, opts ...DialOption) (*grpc.ClientConn, error) {
-	dialOpts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}
+type DialOption func(name string, s *dialSettings) error
 
+func Dial(name string, opts ...DialOption) (*grpc.ClientConn, error) {
+	var settings dialSettings
 	for _, fn := range opts {
-		opt, err := fn(name)
-		if err != nil {
+		if err := fn(name, &settings); err != nil {
 			return nil, fmt.Errorf("config error: %v", err)
 		}
-		dialOpts = append(dialOpts, opt)
 	}
 
+	creds := insecure.NewCredentials()
+	if settings.tls != nil {
+		creds = credentials.NewTLS(settings.tls)
+	}
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, settings.options...)
+
 	conn, err := grpc.Dial(name, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial %s: %v", name, err)
@@ -30,13 +41,103 @@ func Dial(name string, opts ...DialOption) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
-// dial with ssl
+// WithUnaryClientInterceptors chains the interceptors on every unary call of the connection.
+func WithUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) DialOption {
+	return func(name string, s *dialSettings) error {
+		s.options = append(s.options, grpc.WithChainUnaryInterceptor(interceptors...))
+		return nil
+	}
+}
+
+// WithTLS dials over TLS, trusting the CAs in caFile or the system roots when it is empty.
+func WithTLS(caFile string) DialOption {
+	return func(name string, s *dialSettings) error {
+		s.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+		if caFile == "" {
+			return nil
+		}
+
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return err
+		}
+		s.tls.RootCAs = pool
+
+		return nil
+	}
+}
+
+// WithServerName and WithClientCertificate apply to the TLS settings of WithTLS, so they
+// must come after it.
 
-func InitGRPCConn(addr string, ssl bool, cert string) *grpc.ClientConn {
-	// if ssl true, dial with ssl
+// WithServerName sends serverName as SNI and expects it in the server certificate, for
+// servers reached through an address their certificate does not name. Empty keeps the
+// host of the address.
+func WithServerName(serverName string) DialOption {
+	return func(name string, s *dialSettings) error {
+		if serverName == "" {
+			return nil
+		}
+		if s.tls == nil {
+			return fmt.Errorf("server name %s is set but %s is not dialed over TLS", serverName, name)
+		}
+		s.tls.ServerName = serverName
+
+		return nil
+	}
+}
+
+// WithClientCertificate presents the certificate in certFile and keyFile to servers that
+// require mutual TLS. Empty files present none.
+func WithClientCertificate(certFile, keyFile string) DialOption {
+	return func(name string, s *dialSettings) error {
+		if certFile == "" && keyFile == "" {
+			return nil
+		}
+		if s.tls == nil {
+			return fmt.Errorf("client certificate is set but %s is not dialed over TLS", name)
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		s.tls.Certificates = []tls.Certificate{cert}
+
+		return nil
+	}
+}
+
+// WithSelfCertificate dials this server itself, as the REST gateway does. The server is
+// trusted when it presents the certificate of certs, which is also presented as client
+// certificate so the connection passes mutual TLS.
+func WithSelfCertificate(certs *CertReloader) DialOption {
+	return func(name string, s *dialSettings) error {
+		s.tls = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// the address is loopback, which the certificate does not name, the pin below
+			// replaces the usual verification
+			InsecureSkipVerify:   true,
+			GetClientCertificate: certs.GetClientCertificate,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 || !isCertificate(rawCerts[0], certs.Certificate()) {
+					return fmt.Errorf("server did not present its own certificate")
+				}
+				return nil
+			},
+		}
+
+		return nil
+	}
+}
+
+// InitGRPCConn dials addr, over TLS when ssl is set with cert as CA file, see WithTLS.
+func InitGRPCConn(addr string, ssl bool, cert string, opts ...DialOption) *grpc.ClientConn {
+	if ssl {
+		opts = append([]DialOption{WithTLS(cert)}, opts...)
+	}
 
-	// else
-	conn, err := Dial(addr)
+	conn, err := Dial(addr, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("ERROR: dial error: %v", err))
 	}
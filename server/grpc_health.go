@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GrpcHealth serves grpc.health.v1.Health. Every check is exposed as its own service name
+// (e.g. "mysql"), the empty name and the registered gRPC services report the overall status.
+type GrpcHealth struct {
+	Server   *health.Server
+	checks   map[string]HealthCheck
+	services []string
+	interval time.Duration
+}
+
+func NewGrpcHealth(checks map[string]HealthCheck, interval time.Duration) *GrpcHealth {
+	return &GrpcHealth{
+		Server:   health.NewServer(),
+		checks:   checks,
+		interval: interval,
+	}
+}
+
+// Register must be called after every other service is registered on server.
+func (h *GrpcHealth) Register(server *grpc.Server) {
+	for name := range server.GetServiceInfo() {
+		h.services = append(h.services, name)
+	}
+	healthpb.RegisterHealthServer(server, h.Server)
+}
+
+// Start runs the checks right away and then on every interval until ctx is done.
+func (h *GrpcHealth) Start(ctx context.Context) {
+	h.update(ctx)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.update(ctx)
+		}
+	}
+}
+
+func (h *GrpcHealth) update(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for name, err := range RunHealthChecks(checkCtx, h.checks) {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			slog.WarnContext(ctx, "[GrpcHealth - update] Health check failed", "check", name, "error", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		h.Server.SetServingStatus(name, status)
+	}
+
+	h.Server.SetServingStatus("", overall)
+	for _, service := range h.services {
+		h.Server.SetServingStatus(service, overall)
+	}
+}
+
+// Stop reports NOT_SERVING for every service so clients stop routing to this instance.
+func (h *GrpcHealth) Stop(ctx context.Context) error {
+	h.Server.Shutdown()
+	return nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWebAllowedHeaders are the request headers browsers may send in a CORS request.
+var grpcWebAllowedHeaders = []string{
+	"content-type",
+	"x-grpc-web",
+	"x-user-agent",
+	"grpc-timeout",
+	"authorization",
+	"x-request-id",
+}
+
+// GrpcWeb serves the gRPC server to browsers through the gRPC-Web protocol,
+// including CORS preflight requests, so no separate proxy is needed.
+type GrpcWeb struct {
+	Server   *http.Server
+	listener net.Listener
+	Port     string
+}
+
+// NewGrpcWeb wraps grpcServer. An allowed origin of "*" accepts every origin. It serves
+// over TLS when tlsConfig is not nil, see NewHTTPTLSConfig.
+func NewGrpcWeb(port string, grpcServer *grpc.Server, allowedOrigins []string, tlsConfig *tls.Config) *GrpcWeb {
+	wrapped := grpcweb.WrapServer(grpcServer,
+		grpcweb.WithOriginFunc(originMatcher(allowedOrigins)),
+		grpcweb.WithAllowedRequestHeaders(grpcWebAllowedHeaders),
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	return &GrpcWeb{
+		Server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+			TLSConfig:         tlsConfig,
+		},
+		Port: port,
+	}
+}
+
+func originMatcher(allowedOrigins []string) func(origin string) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			return func(string) bool { return true }
+		}
+		allowed[origin] = true
+	}
+
+	return func(origin string) bool {
+		return allowed[origin]
+	}
+}
+
+func (g *GrpcWeb) Run() error {
+	var err error
+	g.listener, err = net.Listen(connProtocol, fmt.Sprintf(":%s", g.Port))
+	if err != nil {
+		return status.Errorf(codes.Internal, "ERROR: Failed to listen on port %s: %v", g.Port, err)
+	}
+
+	go g.serve()
+	slog.Info("grpc-web server is running", "port", g.Port, "tls", g.Server.TLSConfig != nil)
+	return nil
+}
+
+func (g *GrpcWeb) serve() {
+	if err := serveHTTP(g.Server, g.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
+}
+
+func (g *GrpcWeb) Stop(ctx context.Context) error {
+	return g.Server.Shutdown(ctx)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+const (
+	healthCheckTimeout = 5 * time.Second
+)
+
+// HealthCheck reports whether one dependency of the service is usable.
+type HealthCheck func(ctx context.Context) error
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LivenessHandler answers 200 as long as the process is able to serve HTTP.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, &healthResponse{Status: "ok"})
+	})
+}
+
+// ReadinessHandler runs every check and answers 503 when at least one fails.
+func ReadinessHandler(checks map[string]HealthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		results := RunHealthChecks(ctx, checks)
+
+		res := &healthResponse{Status: "ok", Checks: make(map[string]string, len(results))}
+		code := http.StatusOK
+		for name, err := range results {
+			if err != nil {
+				res.Checks[name] = err.Error()
+				res.Status = "unavailable"
+				code = http.StatusServiceUnavailable
+				continue
+			}
+			res.Checks[name] = "ok"
+		}
+
+		writeHealth(w, code, res)
+	})
+}
+
+// RunHealthChecks runs the checks concurrently and returns the error of each, nil when healthy.
+func RunHealthChecks(ctx context.Context, checks map[string]HealthCheck) map[string]error {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error, len(checks))
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			err := check(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, checks[name])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ConnectionCheck fails unless conn is connected, an idle connection is asked to connect first.
+func ConnectionCheck(conn *grpc.ClientConn) HealthCheck {
+	return func(ctx context.Context) error {
+		for {
+			state := conn.GetState()
+			switch state {
+			case connectivity.Ready:
+				return nil
+			case connectivity.Idle:
+				conn.Connect()
+			case connectivity.TransientFailure, connectivity.Shutdown:
+				return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+			}
+
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+			}
+		}
+	}
+}
+
+func writeHealth(w http.ResponseWriter, code int, res *healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(res)
+}
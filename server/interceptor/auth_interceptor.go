@@ -2,34 +2,41 @@ package interceptor
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"strings"
 
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/errors"
 	commonJwt "tracerstudy-post-service/common/jwt"
 	"tracerstudy-post-service/common/utils"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/metadata"
 )
 
 type AuthInterceptor struct {
-	jwtManager      *commonJwt.JWT
-	accessibleRoles map[string][]uint32
+	jwtManager  *commonJwt.JWT
+	policy      *authorization.PolicyStore
+	revocations RevocationChecker
 }
 
-func NewAuthInterceptor(jwtManager *commonJwt.JWT, accessibleRoles map[string][]uint32) *AuthInterceptor {
+// RevocationChecker tells whether a token that verified fine has been revoked since.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *commonJwt.CustomClaims) (bool, error)
+}
+
+func NewAuthInterceptor(jwtManager *commonJwt.JWT, policy *authorization.PolicyStore, revocations RevocationChecker) *AuthInterceptor {
 	return &AuthInterceptor{
-		jwtManager:      jwtManager,
-		accessibleRoles: accessibleRoles,
+		jwtManager:  jwtManager,
+		policy:      policy,
+		revocations: revocations,
 	}
 }
 
 func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		log.Println("INFO [Auth Interceptor - Unary Server Interceptor] Method:", info.FullMethod)
-
-		if err := a.authorize(ctx, info.FullMethod); err != nil {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
 			return nil, err
 		}
 
@@ -37,38 +44,77 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	}
 }
 
-func (a *AuthInterceptor) authorize(ctx context.Context, method string) error {
-	accessibleRoles, ok := a.accessibleRoles[method]
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize returns ctx with the caller's principal. Public methods are let through
+// anonymously, but still get a principal when a valid token is sent. Methods the policy
+// does not mention are denied.
+func (a *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	public, accessibleRoles, ok := a.policy.Policy().Rule(method)
 	if !ok {
-		return nil
+		slog.WarnContext(ctx, "[Auth Interceptor - Authorize] Method is not in the policy", "method", method)
+		return ctx, errors.Forbidden(errors.ReasonPermissionDenied, nil, "method is not allowed by the policy").WithMetadata("method", method)
+	}
+
+	if public {
+		if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) == 0 {
+			return ctx, nil
+		}
+		if claims, err := a.verify(ctx); err == nil {
+			ctx = authorization.WithPrincipal(ctx, authorization.NewPrincipal(claims))
+		}
+		return ctx, nil
+	}
+
+	claims, err := a.verify(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "[Auth Interceptor - Authorize] Error while verify access token", "error", err)
+		return ctx, err
 	}
 
+	for _, role := range accessibleRoles {
+		if role == claims.Role {
+			return authorization.WithPrincipal(ctx, authorization.NewPrincipal(claims)), nil
+		}
+	}
+
+	slog.ErrorContext(ctx, "[Auth Interceptor - Authorize] No permission to access this RPC")
+	return ctx, errors.Forbidden(errors.ReasonPermissionDenied, nil, "no permission to access this RPC").WithMetadata("method", method)
+}
+
+func (a *AuthInterceptor) verify(ctx context.Context) (*commonJwt.CustomClaims, error) {
 	authHeader, err := utils.GetMetadataAuthorization(ctx)
 	if err != nil {
-		log.Println("ERROR: [Auth Interceptor - Authorize] Error while getting metadata authorization:", err)
-		return status.Errorf(codes.Unauthenticated, "error while get metadata authorization: %v", err)
+		return nil, err
 	}
 
 	parts := strings.Fields(authHeader)
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		log.Println("ERROR: [Auth Interceptor - Authorize] Authorization token in wrong format")
-		return status.Errorf(codes.Unauthenticated, "authorization token is invalid")
+		return nil, errors.Unauthenticated(errors.ReasonInvalidToken, nil, "authorization token is invalid")
 	}
 
-	accessToken := parts[1]
-
-	claims, err := a.jwtManager.Verify(accessToken)
+	claims, err := a.jwtManager.Verify(parts[1])
 	if err != nil {
-		log.Println("ERROR: [Auth Interceptor - Authorize] Access token is invalid:", err)
-		return status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
+		return nil, errors.Unauthenticated(errors.ReasonInvalidToken, err, "access token is invalid")
 	}
 
-	for _, role := range accessibleRoles {
-		if role == claims.Role {
-			return nil
-		}
+	revoked, err := a.revocations.IsRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		slog.WarnContext(ctx, "[Auth Interceptor - Verify] Revoked token used", "jti", claims.ID, "user_id", claims.UserId, "cred", claims.Cred)
+		return nil, errors.Unauthenticated(errors.ReasonTokenRevoked, nil, "access token has been revoked")
 	}
 
-	log.Println("ERROR: [Auth Interceptor - Authorize] No permission to access this RPC")
-	return status.Errorf(codes.PermissionDenied, "no permission to access this RPC")
+	return claims, nil
 }
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DeadlineInterceptor bounds RPCs whose caller did not set a deadline. A caller deadline
+// is always kept, even when it is longer than the default.
+type DeadlineInterceptor struct {
+	defaultTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+}
+
+func NewDeadlineInterceptor(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) *DeadlineInterceptor {
+	return &DeadlineInterceptor{
+		defaultTimeout: defaultTimeout,
+		methodTimeouts: methodTimeouts,
+	}
+}
+
+func (d *DeadlineInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := d.withDeadline(ctx, info.FullMethod)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream only applies explicit per-method timeouts, streams are usually meant to stay open.
+func (d *DeadlineInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := d.methodTimeouts[info.FullMethod]; !ok {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := d.withDeadline(ss.Context(), info.FullMethod)
+		defer cancel()
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (d *DeadlineInterceptor) withDeadline(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	timeout, ok := d.methodTimeouts[method]
+	if !ok {
+		timeout = d.defaultTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
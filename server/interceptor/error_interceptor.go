@@ -0,0 +1,51 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+
+	"tracerstudy-post-service/common/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorInterceptor converts the errors returned by handlers into gRPC statuses. It is the only
+// place where errors.ToStatus is called, handlers and services just return the error they got.
+type ErrorInterceptor struct{}
+
+func NewErrorInterceptor() *ErrorInterceptor {
+	return &ErrorInterceptor{}
+}
+
+func (e *ErrorInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		res, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, info.FullMethod, err)
+		}
+
+		return res, nil
+	}
+}
+
+func (e *ErrorInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatus(ss.Context(), info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+func toStatus(ctx context.Context, method string, err error) error {
+	converted := errors.ToStatus(err)
+	if status.Code(converted) == codes.Internal {
+		// the client only sees a generic message, keep the cause in the logs
+		slog.ErrorContext(ctx, "[Error Interceptor] Unexpected error", "method", method, "error", err)
+	}
+
+	return converted
+}
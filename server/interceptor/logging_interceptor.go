@@ -0,0 +1,59 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingInterceptor writes one access log line per RPC. Failures caused by the server
+// are logged at error level, everything else at info.
+type LoggingInterceptor struct{}
+
+func NewLoggingInterceptor() *LoggingInterceptor {
+	return &LoggingInterceptor{}
+}
+
+func (l *LoggingInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logAccess(ctx, info.FullMethod, start, err)
+
+		return res, err
+	}
+}
+
+func (l *LoggingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logAccess(ss.Context(), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logAccess(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []any{
+		"method", method,
+		"code", code.String(),
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented:
+		slog.ErrorContext(ctx, "[Access Log] RPC failed", append(attrs, "error", status.Convert(err).Message())...)
+	default:
+		slog.InfoContext(ctx, "[Access Log] RPC handled", attrs...)
+	}
+}
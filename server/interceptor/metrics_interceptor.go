@@ -0,0 +1,43 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"tracerstudy-post-service/common/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type MetricsInterceptor struct{}
+
+func NewMetricsInterceptor() *MetricsInterceptor {
+	return &MetricsInterceptor{}
+}
+
+func (m *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		observeRPC(info.FullMethod, start, err)
+
+		return res, err
+	}
+}
+
+func (m *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRPC(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metrics.RPCDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+	metrics.RPCHandled.WithLabelValues(method, code).Inc()
+}
@@ -0,0 +1,109 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/ratelimit"
+	"tracerstudy-post-service/common/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RateLimitInterceptor gives every caller a token bucket per method. Callers are told
+// apart by their principal when they sent a valid token, by client address otherwise.
+// It runs after AuthInterceptor so authenticated users are not limited by a shared IP.
+type RateLimitInterceptor struct {
+	store          ratelimit.Store
+	enabled        bool
+	defaultLimit   ratelimit.Limit
+	methodLimits   map[string]ratelimit.Limit
+	trustedProxies int
+}
+
+func NewRateLimitInterceptor(cfg config.Config, store ratelimit.Store) *RateLimitInterceptor {
+	methodLimits := make(map[string]ratelimit.Limit, len(cfg.RateLimit.Methods))
+	for method, rate := range cfg.RateLimit.Methods {
+		methodLimits[method] = ratelimit.Limit(rate)
+	}
+
+	return &RateLimitInterceptor{
+		store:          store,
+		enabled:        cfg.RateLimit.Enabled,
+		defaultLimit:   ratelimit.Limit(cfg.RateLimit.Default),
+		methodLimits:   methodLimits,
+		trustedProxies: cfg.HTTP.TrustedProxies,
+	}
+}
+
+func (r *RateLimitInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := r.take(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream takes a token when the stream is opened, not per message.
+func (r *RateLimitInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := r.take(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func (r *RateLimitInterceptor) take(ctx context.Context, method string) error {
+	if !r.enabled {
+		return nil
+	}
+
+	limit, ok := r.methodLimits[method]
+	if !ok {
+		limit = r.defaultLimit
+	}
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return nil
+	}
+
+	caller := r.caller(ctx)
+	res, err := r.store.Take(ctx, method+"|"+caller, limit)
+	if err != nil {
+		// an unreachable store must not take the whole service down with it
+		slog.ErrorContext(ctx, "[RateLimit Interceptor] Error while take token, letting the request through", "method", method, "error", err)
+		return nil
+	}
+	if res.Allowed {
+		return nil
+	}
+
+	retryAfter := strconv.FormatInt(errors.RetryAfterSeconds(res.RetryAfter), 10)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+
+	slog.WarnContext(ctx, "[RateLimit Interceptor] Rate limit exceeded", "method", method, "caller", caller, "retry_after", res.RetryAfter)
+	return errors.ResourceExhausted(errors.ReasonRateLimited, nil, "too many requests, retry in %s seconds", retryAfter).
+		WithRetryAfter(res.RetryAfter).
+		WithMetadata("method", method)
+}
+
+func (r *RateLimitInterceptor) caller(ctx context.Context) string {
+	if principal, ok := authorization.PrincipalFromContext(ctx); ok {
+		if principal.UserId != 0 {
+			return "user:" + strconv.FormatUint(principal.UserId, 10)
+		}
+		if principal.Cred != "" {
+			return "cred:" + principal.Cred
+		}
+	}
+
+	return "ip:" + utils.ClientIP(ctx, r.trustedProxies)
+}
@@ -0,0 +1,160 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"tracerstudy-post-service/common/authorization"
+	"tracerstudy-post-service/common/config"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/ratelimit"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const limitedMethod = "/tracer_study_grpc.CommentService/CreateComment"
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, context.DeadlineExceeded
+}
+
+func callerContext(t *testing.T, addr string, forwardedFor string, principal *authorization.Principal) context.Context {
+	t.Helper()
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr})
+	if forwardedFor != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", forwardedFor))
+	}
+	if principal != nil {
+		ctx = authorization.WithPrincipal(ctx, principal)
+	}
+
+	return ctx
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	cfg := config.Config{
+		RateLimit: config.RateLimit{
+			Enabled: true,
+			Methods: config.MethodRates{limitedMethod: {Requests: 1, Period: time.Minute}},
+		},
+	}
+	user := &authorization.Principal{UserId: 7, Role: authorization.RoleAlumni}
+
+	tests := []struct {
+		name string
+		// first uses up the bucket, second is then allowed or not
+		first, second context.Context
+		method        string
+		wantAllowed   bool
+	}{
+		{
+			name:   "same address",
+			first:  callerContext(t, "203.0.113.7:1000", "", nil),
+			second: callerContext(t, "203.0.113.7:2000", "", nil),
+			method: limitedMethod,
+		},
+		{
+			name:        "other address",
+			first:       callerContext(t, "203.0.113.7:1000", "", nil),
+			second:      callerContext(t, "203.0.113.8:1000", "", nil),
+			method:      limitedMethod,
+			wantAllowed: true,
+		},
+		{
+			name:   "spoofed forwarded address through the gateway",
+			first:  callerContext(t, "127.0.0.1:1000", "10.0.0.1, 198.51.100.1", nil),
+			second: callerContext(t, "127.0.0.1:1000", "10.0.0.2, 198.51.100.1", nil),
+			method: limitedMethod,
+		},
+		{
+			name:        "user behind an address that used its bucket up",
+			first:       callerContext(t, "203.0.113.7:1000", "", nil),
+			second:      callerContext(t, "203.0.113.7:1000", "", user),
+			method:      limitedMethod,
+			wantAllowed: true,
+		},
+		{
+			name:   "same user from another address",
+			first:  callerContext(t, "203.0.113.7:1000", "", user),
+			second: callerContext(t, "203.0.113.8:1000", "", user),
+			method: limitedMethod,
+		},
+		{
+			name:        "method without limit",
+			first:       callerContext(t, "203.0.113.7:1000", "", nil),
+			second:      callerContext(t, "203.0.113.7:1000", "", nil),
+			method:      "/tracer_study_grpc.PostService/GetAllPosts",
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRateLimitInterceptor(cfg, ratelimit.NewMemoryStore())
+
+			if err := r.take(tt.first, tt.method); err != nil {
+				t.Fatalf("first take() error = %v", err)
+			}
+
+			err := r.take(tt.second, tt.method)
+			if tt.wantAllowed {
+				if err != nil {
+					t.Fatalf("second take() error = %v", err)
+				}
+				return
+			}
+
+			var e *errors.Error
+			if !errors.As(err, &e) || !errors.Is(err, errors.ErrResourceExhausted) {
+				t.Fatalf("second take() error = %v, want resource exhausted", err)
+			}
+			if e.Reason != errors.ReasonRateLimited || e.RetryAfter <= 0 {
+				t.Fatalf("second take() reason = %s, retry after %s", e.Reason, e.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimitInterceptorLetsRequestsThrough(t *testing.T) {
+	limits := config.MethodRates{limitedMethod: {Requests: 1, Period: time.Minute}}
+
+	tests := []struct {
+		name  string
+		cfg   config.RateLimit
+		store ratelimit.Store
+	}{
+		{
+			name:  "disabled",
+			cfg:   config.RateLimit{Methods: limits},
+			store: ratelimit.NewMemoryStore(),
+		},
+		{
+			name:  "store unavailable",
+			cfg:   config.RateLimit{Enabled: true, Methods: limits},
+			store: failingStore{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRateLimitInterceptor(config.Config{RateLimit: tt.cfg}, tt.store)
+			ctx := callerContext(t, "203.0.113.7:1000", "", nil)
+
+			for i := 0; i < 3; i++ {
+				if err := r.take(ctx, limitedMethod); err != nil {
+					t.Fatalf("take() %d error = %v", i, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package interceptor
+
+import (
+	"context"
+
+	"tracerstudy-post-service/common/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDInterceptor reuses the x-request-id sent by the caller or generates one, stores it in
+// the context so every log line of the request carries it and returns it in the response header.
+type RequestIDInterceptor struct{}
+
+func NewRequestIDInterceptor() *RequestIDInterceptor {
+	return &RequestIDInterceptor{}
+}
+
+func (r *RequestIDInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := incomingRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDHeader, requestID))
+
+		return handler(logger.WithRequestID(ctx, requestID), req)
+	}
+}
+
+func (r *RequestIDInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requestID := incomingRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(logger.RequestIDHeader, requestID))
+
+		ctx := logger.WithRequestID(ss.Context(), requestID)
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func incomingRequestID(ctx context.Context) string {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(logger.RequestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	return logger.NewRequestID(requestID)
+}
@@ -0,0 +1,84 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"tracerstudy-post-service/common/errors"
+	"tracerstudy-post-service/common/validation"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// validator is implemented by request messages that can check their own fields.
+type validator interface {
+	Validate() error
+}
+
+// ValidationInterceptor rejects requests that break the rules declared in the proto files,
+// or whose Validate method fails, with InvalidArgument.
+type ValidationInterceptor struct {
+	validator *validation.Validator
+}
+
+func NewValidationInterceptor(validator *validation.Validator) *ValidationInterceptor {
+	return &ValidationInterceptor{
+		validator: validator,
+	}
+}
+
+func (v *ValidationInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := v.validate(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream validates every message received from the client.
+func (v *ValidationInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{ServerStream: ss, interceptor: v, method: info.FullMethod})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+	interceptor *ValidationInterceptor
+	method      string
+}
+
+func (s *validatingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.interceptor.validate(s.Context(), s.method, m)
+}
+
+func (v *ValidationInterceptor) validate(ctx context.Context, method string, req any) error {
+	if msg, ok := req.(proto.Message); ok {
+		if err := v.validator.Validate(ctx, method, msg); err != nil {
+			slog.WarnContext(ctx, "[Validation Interceptor] Invalid request", "method", method, "error", err)
+			return err
+		}
+	}
+
+	r, ok := req.(validator)
+	if !ok {
+		return nil
+	}
+
+	if err := r.Validate(); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		slog.WarnContext(ctx, "[Validation Interceptor] Invalid request", "error", err)
+		return errors.Validation(errors.ReasonInvalidRequest, err, "invalid request: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+)
+
+type Rest struct {
+	Server   *http.Server
+	Mux      *http.ServeMux
+	listener net.Listener
+	Port     string
+}
+
+// NewRest serves over TLS when tlsConfig is not nil, see NewHTTPTLSConfig.
+func NewRest(port string, tlsConfig *tls.Config) *Rest {
+	mux := http.NewServeMux()
+
+	return &Rest{
+		Server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+			TLSConfig:         tlsConfig,
+		},
+		Mux:  mux,
+		Port: port,
+	}
+}
+
+func (r *Rest) Run() error {
+	var err error
+	r.listener, err = net.Listen(connProtocol, fmt.Sprintf(":%s", r.Port))
+	if err != nil {
+		return status.Errorf(codes.Internal, "ERROR: Failed to listen on port %s: %v", r.Port, err)
+	}
+
+	go r.serve()
+	slog.Info("rest server is running", "port", r.Port, "tls", r.Server.TLSConfig != nil)
+	return nil
+}
+
+func (r *Rest) serve() {
+	if err := serveHTTP(r.Server, r.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
+}
+
+// serveHTTP serves over TLS when srv has a TLS config, its GetCertificate provides the
+// certificate so no files are passed.
+func serveHTTP(srv *http.Server, listener net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(listener, "", "")
+	}
+
+	return srv.Serve(listener)
+}
+
+func (r *Rest) Stop(ctx context.Context) error {
+	return r.Server.Shutdown(ctx)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Stopper is a server that can be stopped gracefully. Stop must return once ctx is done.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// AwaitTermination blocks until SIGINT or SIGTERM, then stops every server in parallel
+// and gives in-flight requests up to timeout to finish.
+func AwaitTermination(timeout time.Duration, servers ...Stopper) error {
+	sign := make(chan os.Signal, 1)
+	signal.Notify(sign, syscall.SIGINT, syscall.SIGTERM)
+	<-sign
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(servers))
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s Stopper) {
+			defer wg.Done()
+			errs[i] = s.Stop(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			slog.Error("[Server - AwaitTermination] Error while stopping server", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tracerstudy-post-service/common/config"
+)
+
+// CertReloader serves a certificate loaded from files and reloads it when they change,
+// so a renewed certificate is picked up without a restart. A pair that fails to load
+// leaves the previous certificate in place.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	current  atomic.Pointer[tls.Certificate]
+
+	mu       sync.Mutex
+	modTimes [2]time.Time
+	sizes    [2]int64
+}
+
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both a certificate and a key file are required")
+	}
+
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Certificate returns the certificate currently served.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	return r.current.Load()
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+// Watch checks the files for changes every interval until ctx is done.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "[CertReloader - Watch] Error while reload certificate, keeping the previous one", "cert", r.certFile, "error", err)
+				continue
+			}
+			if changed {
+				slog.InfoContext(ctx, "[CertReloader - Watch] Certificate reloaded", "cert", r.certFile, "expires", r.Certificate().Leaf.NotAfter)
+			}
+		}
+	}
+}
+
+func (r *CertReloader) reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modTimes [2]time.Time
+	var sizes [2]int64
+	for i, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		modTimes[i], sizes[i] = info.ModTime(), info.Size()
+	}
+	if modTimes == r.modTimes && sizes == r.sizes {
+		return false, nil
+	}
+
+	// the files are replaced one after the other, a mismatched pair fails here and is
+	// retried on the next tick, so the failure is not remembered
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return false, err
+		}
+	}
+
+	r.current.Store(&cert)
+	r.modTimes, r.sizes = modTimes, sizes
+
+	return true, nil
+}
+
+// NewServerTLSConfig serves the certificate of certs. With a client CA, clients must
+// present a certificate it signed, or may present none when it is optional.
+func NewServerTLSConfig(cfg config.TLS, certs *CertReloader) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	clientCAs, err := loadCertPool(cfg.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// verified below instead of through ClientCAs, to also accept the server's own
+	// certificate from the REST gateway, see WithSelfCertificate
+	tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	if cfg.ClientCertOptional {
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return verifyClientCertificate(rawCerts, clientCAs, certs)
+	}
+
+	return tlsConfig, nil
+}
+
+// NewHTTPTLSConfig serves the certificate of certs on the REST and gRPC-Web ports.
+// Browsers do not present client certificates, so mutual TLS stays on the gRPC port.
+func NewHTTPTLSConfig(certs *CertReloader) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+}
+
+func verifyClientCertificate(rawCerts [][]byte, clientCAs *x509.CertPool, certs *CertReloader) error {
+	if len(rawCerts) == 0 {
+		return nil
+	}
+	if isCertificate(rawCerts[0], certs.Certificate()) {
+		return nil
+	}
+
+	chain := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("invalid client certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("client certificate is not trusted: %w", err)
+	}
+
+	return nil
+}
+
+func isCertificate(raw []byte, cert *tls.Certificate) bool {
+	return cert != nil && len(cert.Certificate) > 0 && bytes.Equal(raw, cert.Certificate[0])
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	return pool, nil
+}
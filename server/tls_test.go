@@ -0,0 +1,332 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tracerstudy-post-service/common/config"
+)
+
+// testCA signs the certificates of a test, certificates signed by another testCA are
+// not trusted by it.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue returns the PEM encoded certificate and key of a leaf valid for localhost.
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) keyPair(t *testing.T, serial int64, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+
+	certPEM, keyPEM := ca.issue(t, serial, usage)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestCertReloader serves a server certificate of ca with serial 1.
+func newTestCertReloader(t *testing.T, ca *testCA) (*CertReloader, string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	certPEM, keyPEM := ca.issue(t, 1, x509.ExtKeyUsageServerAuth)
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, certFile, certPEM, start)
+	writeFile(t, keyFile, keyPEM, start)
+
+	certs, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	return certs, certFile, keyFile
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	ca := newTestCA(t, "server ca")
+	certs, certFile, keyFile := newTestCertReloader(t, ca)
+	serial := func() int64 { return certs.Certificate().Leaf.SerialNumber.Int64() }
+
+	if changed, err := certs.reload(); changed || err != nil {
+		t.Fatalf("reload() of unchanged files = %v, %v, want false, nil", changed, err)
+	}
+
+	renewed := time.Now().Add(-30 * time.Minute)
+	certPEM, keyPEM := ca.issue(t, 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, renewed)
+	writeFile(t, keyFile, keyPEM, renewed)
+	if changed, err := certs.reload(); !changed || err != nil {
+		t.Fatalf("reload() of a renewed pair = %v, %v, want true, nil", changed, err)
+	}
+	if serial() != 2 {
+		t.Fatalf("serial after the renewal = %d, want 2", serial())
+	}
+
+	// a certificate written before its key does not match the previous key
+	certPEM, keyPEM = ca.issue(t, 3, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, renewed.Add(time.Minute))
+	if _, err := certs.reload(); err == nil {
+		t.Fatal("reload() of a mismatched pair error = nil")
+	}
+	if serial() != 2 {
+		t.Fatalf("serial after a mismatched pair = %d, want the previous 2", serial())
+	}
+
+	writeFile(t, keyFile, []byte("garbage"), renewed.Add(time.Minute))
+	if _, err := certs.reload(); err == nil {
+		t.Fatal("reload() of a garbage key error = nil")
+	}
+	if serial() != 2 {
+		t.Fatalf("serial after a garbage key = %d, want the previous 2", serial())
+	}
+
+	// the failures are not remembered, the completed pair loads on the next try
+	writeFile(t, keyFile, keyPEM, renewed.Add(2*time.Minute))
+	if changed, err := certs.reload(); !changed || err != nil {
+		t.Fatalf("reload() of the completed pair = %v, %v, want true, nil", changed, err)
+	}
+	if serial() != 3 {
+		t.Fatalf("serial after the completed pair = %d, want 3", serial())
+	}
+}
+
+func TestNewCertReloaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage")
+	writeFile(t, garbage, []byte("garbage"), time.Now())
+
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+	}{
+		{name: "no files"},
+		{name: "missing files", certFile: filepath.Join(dir, "tls.crt"), keyFile: filepath.Join(dir, "tls.key")},
+		{name: "garbage", certFile: garbage, keyFile: garbage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCertReloader(tt.certFile, tt.keyFile); err == nil {
+				t.Fatal("NewCertReloader() error = nil")
+			}
+		})
+	}
+}
+
+// handshake connects a client presenting clientCerts and returns the server's error.
+func handshake(t *testing.T, serverConfig *tls.Config, serverCA *testCA, clientCerts []tls.Certificate) error {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, serverConfig).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+		RootCAs:      serverCA.pool(),
+		ServerName:   "localhost",
+		Certificates: clientCerts,
+	})
+	if err == nil {
+		defer conn.Close()
+	}
+
+	return <-serverErr
+}
+
+func TestServerTLSConfigClientAuth(t *testing.T) {
+	serverCA := newTestCA(t, "server ca")
+	clientCA := newTestCA(t, "client ca")
+	otherCA := newTestCA(t, "other ca")
+	certs, _, _ := newTestCertReloader(t, serverCA)
+
+	clientCAFile := filepath.Join(t.TempDir(), "client-ca.crt")
+	writeFile(t, clientCAFile, clientCA.pem, time.Now())
+
+	trusted := clientCA.keyPair(t, 10, x509.ExtKeyUsageClientAuth)
+	untrusted := otherCA.keyPair(t, 11, x509.ExtKeyUsageClientAuth)
+	serverUsage := clientCA.keyPair(t, 12, x509.ExtKeyUsageServerAuth)
+
+	tests := []struct {
+		name        string
+		cfg         config.TLS
+		clientCerts []tls.Certificate
+		wantErr     bool
+	}{
+		{name: "without client CA", cfg: config.TLS{}},
+		{name: "without client CA ignores client certificates", cfg: config.TLS{}, clientCerts: []tls.Certificate{untrusted}},
+		{name: "trusted client", cfg: config.TLS{ClientCAFile: clientCAFile}, clientCerts: []tls.Certificate{trusted}},
+		{name: "missing client certificate", cfg: config.TLS{ClientCAFile: clientCAFile}, wantErr: true},
+		{name: "untrusted client", cfg: config.TLS{ClientCAFile: clientCAFile}, clientCerts: []tls.Certificate{untrusted}, wantErr: true},
+		{name: "certificate without client auth usage", cfg: config.TLS{ClientCAFile: clientCAFile}, clientCerts: []tls.Certificate{serverUsage}, wantErr: true},
+		{name: "own server certificate", cfg: config.TLS{ClientCAFile: clientCAFile}, clientCerts: []tls.Certificate{*certs.Certificate()}},
+		{name: "optional without client certificate", cfg: config.TLS{ClientCAFile: clientCAFile, ClientCertOptional: true}},
+		{name: "optional still verifies a certificate", cfg: config.TLS{ClientCAFile: clientCAFile, ClientCertOptional: true}, clientCerts: []tls.Certificate{untrusted}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig, err := NewServerTLSConfig(tt.cfg, certs)
+			if err != nil {
+				t.Fatalf("NewServerTLSConfig() error = %v", err)
+			}
+
+			err = handshake(t, serverConfig, serverCA, tt.clientCerts)
+			if tt.wantErr && err == nil {
+				t.Fatal("handshake error = nil, want the client to be rejected")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("handshake error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRestServesTLS(t *testing.T) {
+	ca := newTestCA(t, "server ca")
+	certs, _, _ := newTestCertReloader(t, ca)
+
+	rest := NewRest("0", NewHTTPTLSConfig(certs))
+	rest.Mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "pong")
+	})
+	if err := rest.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	defer rest.Server.Close()
+
+	_, port, _ := net.SplitHostPort(rest.listener.Addr().String())
+	url := "https://localhost:" + port + "/ping"
+
+	plain := &http.Client{Timeout: 5 * time.Second}
+	if res, err := plain.Get("http://localhost:" + port + "/ping"); err == nil {
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			t.Fatal("plaintext request succeeded")
+		}
+	}
+
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool()}},
+	}
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Fatalf("Get() = %d %q, want 200 pong", res.StatusCode, body)
+	}
+	if res.TLS == nil {
+		t.Fatal("response was not served over TLS")
+	}
+}